@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type TimingResponse struct {
+	DNSMs       float64 `json:"dns_ms"`
+	ConnectMs   float64 `json:"connect_ms"`
+	TLSMs       float64 `json:"tls_ms"`
+	FirstByteMs float64 `json:"first_byte_ms"`
+}
+
+type PingResponse struct {
+	Sent       int     `json:"sent"`
+	Received   int     `json:"received"`
+	PacketLoss float64 `json:"packet_loss"`
+	MinMs      float64 `json:"min_ms"`
+	AvgMs      float64 `json:"avg_ms"`
+	MaxMs      float64 `json:"max_ms"`
+}
+
+type UnitResponse struct {
+	ActiveState string     `json:"active_state"`
+	SubState    string     `json:"sub_state"`
+	Result      string     `json:"result,omitempty"`
+	Restarts    int        `json:"restarts"`
+	Since       *time.Time `json:"since,omitempty"`
+}
+
+type ContainerResponse struct {
+	State     string     `json:"state"`
+	Health    string     `json:"health,omitempty"`
+	Restarts  int        `json:"restarts"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+}
+
+type ProcessesResponse struct {
+	Count      int     `json:"count"`
+	CPUPercent float64 `json:"cpu_percent"`
+	RSSBytes   uint64  `json:"rss_bytes"`
+}
+
+type FileResponse struct {
+	Count      int       `json:"count"`
+	Newest     string    `json:"newest"`
+	Modified   time.Time `json:"modified"`
+	AgeSeconds int64     `json:"age_seconds"`
+	SizeBytes  uint64    `json:"size_bytes"`
+	Directory  bool      `json:"directory"`
+}
+
+type DayAvailabilityResponse struct {
+	Date    string   `json:"date"`
+	Percent *float64 `json:"percent"`
+}
+
+type AvailabilityResponse struct {
+	Name   string                    `json:"name"`
+	Uptime map[string]*float64       `json:"uptime"`
+	Daily  []DayAvailabilityResponse `json:"daily"`
+}
+
+type ServiceResponse struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	Status       Status              `json:"status"`
+	Healthy      bool                `json:"healthy"`
+	TimedOut     bool                `json:"timed_out"`
+	Message      string              `json:"message,omitempty"`
+	LatencyMs    float64             `json:"latency_ms"`
+	Timing       TimingResponse      `json:"timing"`
+	Ping         *PingResponse       `json:"ping,omitempty"`
+	Unit         *UnitResponse       `json:"unit,omitempty"`
+	Container    *ContainerResponse  `json:"container,omitempty"`
+	Processes    *ProcessesResponse  `json:"processes,omitempty"`
+	File         *FileResponse       `json:"file,omitempty"`
+	CertExpiry   *time.Time          `json:"cert_expiry,omitempty"`
+	CertDaysLeft *int                `json:"cert_days_left,omitempty"`
+	Certificates []Certificate       `json:"certificates,omitempty"`
+	Uptime       map[string]*float64 `json:"uptime"`
+	Failures     int                 `json:"consecutive_failures"`
+	Successes    int                 `json:"consecutive_successes"`
+	CheckedAt    time.Time           `json:"checked_at"`
+}
+
+type SystemResponse struct {
+	Stats         SystemStats `json:"stats"`
+	UptimeSeconds int64       `json:"uptime_seconds"`
+}
+
+type StatusResponse struct {
+	Site          string            `json:"site"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Stats         SystemStats       `json:"stats"`
+	Services      []ServiceResponse `json:"services"`
+	Counts        map[string]int    `json:"counts"`
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func uptimeResponse(name string) map[string]*float64 {
+	uptime := make(map[string]*float64, len(availabilityWindows))
+	for _, window := range availability(name) {
+		if window.HasData {
+			percent := window.Percent
+			uptime[window.Window] = &percent
+		} else {
+			uptime[window.Window] = nil
+		}
+	}
+
+	return uptime
+}
+
+func availabilityResponse(name string) AvailabilityResponse {
+	days := dailyAvailability(name)
+	daily := make([]DayAvailabilityResponse, 0, len(days))
+	for _, day := range days {
+		response := DayAvailabilityResponse{Date: day.Date.Format(time.DateOnly)}
+		if day.HasData {
+			percent := day.Percent
+			response.Percent = &percent
+		}
+		daily = append(daily, response)
+	}
+
+	return AvailabilityResponse{
+		Name:   name,
+		Uptime: uptimeResponse(name),
+		Daily:  daily,
+	}
+}
+
+func serviceResponses(checks []HealthCheck) []ServiceResponse {
+	services := make([]ServiceResponse, 0, len(checks))
+	for _, check := range checks {
+		var certExpiry *time.Time
+		var certDaysLeft *int
+		if !check.CertExpiry.IsZero() {
+			expiry, daysLeft := check.CertExpiry, check.CertDaysLeft()
+			certExpiry, certDaysLeft = &expiry, &daysLeft
+		}
+
+		var ping *PingResponse
+		if check.Ping.Sent > 0 {
+			ping = &PingResponse{
+				Sent:       check.Ping.Sent,
+				Received:   check.Ping.Received,
+				PacketLoss: check.Ping.Loss(),
+				MinMs:      milliseconds(check.Ping.Min),
+				AvgMs:      milliseconds(check.Ping.Avg),
+				MaxMs:      milliseconds(check.Ping.Max),
+			}
+		}
+
+		var unit *UnitResponse
+		if check.Unit.ActiveState != "" {
+			unit = &UnitResponse{
+				ActiveState: check.Unit.ActiveState,
+				SubState:    check.Unit.SubState,
+				Result:      check.Unit.Result,
+				Restarts:    check.Unit.Restarts,
+			}
+			if !check.Unit.Since.IsZero() {
+				unit.Since = &check.Unit.Since
+			}
+		}
+
+		var container *ContainerResponse
+		if check.Container.State != "" {
+			container = &ContainerResponse{
+				State:    check.Container.State,
+				Health:   check.Container.Health,
+				Restarts: check.Container.Restarts,
+			}
+			if !check.Container.StartedAt.IsZero() {
+				container.StartedAt = &check.Container.StartedAt
+			}
+		}
+
+		var processes *ProcessesResponse
+		if check.Type == "process" && !check.CheckedAt.IsZero() {
+			processes = &ProcessesResponse{
+				Count:      check.Processes.Count,
+				CPUPercent: check.Processes.CPUPercent,
+				RSSBytes:   check.Processes.RSS,
+			}
+		}
+
+		var file *FileResponse
+		if check.File.Count > 0 {
+			file = &FileResponse{
+				Count:      check.File.Count,
+				Newest:     check.File.Newest,
+				Modified:   check.File.Modified,
+				AgeSeconds: int64(time.Since(check.File.Modified).Seconds()),
+				SizeBytes:  check.File.Size,
+				Directory:  check.File.Dir,
+			}
+		}
+
+		services = append(services, ServiceResponse{
+			Name:        check.Name,
+			Description: check.Description,
+			Status:      check.Status,
+			Healthy:     check.Healthy(),
+			TimedOut:    check.TimedOut,
+			Message:     check.Message,
+			LatencyMs:   milliseconds(check.Latency),
+			Timing: TimingResponse{
+				DNSMs:       milliseconds(check.Timing.DNS),
+				ConnectMs:   milliseconds(check.Timing.Connect),
+				TLSMs:       milliseconds(check.Timing.TLS),
+				FirstByteMs: milliseconds(check.Timing.FirstByte),
+			},
+			Ping:         ping,
+			Unit:         unit,
+			Container:    container,
+			Processes:    processes,
+			File:         file,
+			CertExpiry:   certExpiry,
+			CertDaysLeft: certDaysLeft,
+			Certificates: check.Certificates,
+			Uptime:       uptimeResponse(check.Name),
+			Failures:     check.Failures,
+			Successes:    check.Successes,
+			CheckedAt:    check.CheckedAt,
+		})
+	}
+
+	return services
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing JSON response: %v", err)
+	}
+}
+
+type HistoryResponse struct {
+	Series     string   `json:"series"`
+	Resolution string   `json:"resolution"`
+	Samples    []Sample `json:"samples"`
+}
+
+func registerAPI() {
+	http.HandleFunc("GET /api/v1/status", func(w http.ResponseWriter, r *http.Request) {
+		reportMutex.RLock()
+		response := StatusResponse{
+			Site:          config.Site,
+			UptimeSeconds: int64(time.Since(startTime).Seconds()),
+			Stats:         stats,
+			Services:      serviceResponses(healthchecks),
+			Counts:        countStatuses(healthchecks),
+		}
+		reportMutex.RUnlock()
+
+		writeJSON(w, response)
+	})
+
+	http.HandleFunc("GET /api/v1/services", func(w http.ResponseWriter, r *http.Request) {
+		reportMutex.RLock()
+		response := serviceResponses(healthchecks)
+		reportMutex.RUnlock()
+
+		writeJSON(w, response)
+	})
+
+	http.HandleFunc("GET /api/v1/system", func(w http.ResponseWriter, r *http.Request) {
+		reportMutex.RLock()
+		response := SystemResponse{
+			Stats:         stats,
+			UptimeSeconds: int64(time.Since(startTime).Seconds()),
+		}
+		reportMutex.RUnlock()
+
+		writeJSON(w, response)
+	})
+	http.HandleFunc("GET /api/v1/availability", func(w http.ResponseWriter, r *http.Request) {
+		reportMutex.RLock()
+		names := make([]string, 0, len(healthchecks))
+		for _, check := range healthchecks {
+			names = append(names, check.Name)
+		}
+		reportMutex.RUnlock()
+
+		response := make([]AvailabilityResponse, 0, len(names))
+		for _, name := range names {
+			response = append(response, availabilityResponse(name))
+		}
+
+		writeJSON(w, response)
+	})
+
+	http.HandleFunc("GET /api/v1/history", func(w http.ResponseWriter, r *http.Request) {
+		names := history.Names()
+		slices.Sort(names)
+
+		writeJSON(w, names)
+	})
+
+	http.HandleFunc("GET /api/v1/history/{series...}", func(w http.ResponseWriter, r *http.Request) {
+		resolution := r.URL.Query().Get("resolution")
+		if resolution == "" {
+			resolution = "raw"
+		}
+		window := r.URL.Query().Get("window")
+		if window == "" {
+			window = "1h"
+		}
+
+		samples, err := historyWindow(r.PathValue("series"), resolution, window)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if samples == nil {
+			samples = Samples{}
+		}
+
+		writeJSON(w, HistoryResponse{
+			Series:     r.PathValue("series"),
+			Resolution: resolution,
+			Samples:    samples,
+		})
+	})
+}
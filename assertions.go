@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxBodyBytes = 1 << 20
+
+type Assertion struct {
+	Type     string `json:"type"`
+	Path     string `json:"path"`
+	Operator string `json:"operator"`
+	Value    any    `json:"value"`
+}
+
+func checkAssertions(assertions []Assertion, body []byte) error {
+	for _, assertion := range assertions {
+		if err := assertion.check(body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (assertion Assertion) check(body []byte) error {
+	switch assertion.Type {
+	case "contains":
+		value := fmt.Sprint(assertion.Value)
+		if !strings.Contains(string(body), value) {
+			return fmt.Errorf("body does not contain %q", value)
+		}
+	case "regex":
+		pattern, err := regexp.Compile(fmt.Sprint(assertion.Value))
+		if err != nil {
+			return fmt.Errorf("invalid regex assertion: %w", err)
+		}
+		if !pattern.Match(body) {
+			return fmt.Errorf("body does not match %q", pattern)
+		}
+	case "json":
+		var document any
+		if err := json.Unmarshal(body, &document); err != nil {
+			return fmt.Errorf("body is not valid JSON: %w", err)
+		}
+
+		actual, err := lookupJSONPath(document, assertion.Path)
+		if err != nil {
+			return err
+		}
+
+		operator := assertion.Operator
+		if operator == "" {
+			operator = "=="
+		}
+
+		ok, err := compareJSON(actual, operator, assertion.Value)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s is %v, expected %s %v", assertion.Path, actual, operator, assertion.Value)
+		}
+	default:
+		return fmt.Errorf("unknown assertion type %q", assertion.Type)
+	}
+
+	return nil
+}
+
+func lookupJSONPath(document any, path string) (any, error) {
+	current := document
+	for _, key := range strings.Split(strings.TrimPrefix(path, "$."), ".") {
+		if key == "" || key == "$" {
+			continue
+		}
+
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("%s not found in body", path)
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("%s not found in body", path)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("%s not found in body", path)
+		}
+	}
+
+	return current, nil
+}
+
+func compareJSON(actual any, operator string, expected any) (bool, error) {
+	actualNumber, actualIsNumber := actual.(float64)
+	expectedNumber, expectedIsNumber := expected.(float64)
+
+	switch operator {
+	case "==":
+		return reflect.DeepEqual(actual, expected), nil
+	case "!=":
+		return !reflect.DeepEqual(actual, expected), nil
+	}
+
+	if !actualIsNumber || !expectedIsNumber {
+		return false, fmt.Errorf("operator %s needs numbers, got %v and %v", operator, actual, expected)
+	}
+
+	switch operator {
+	case "<":
+		return actualNumber < expectedNumber, nil
+	case "<=":
+		return actualNumber <= expectedNumber, nil
+	case ">":
+		return actualNumber > expectedNumber, nil
+	case ">=":
+		return actualNumber >= expectedNumber, nil
+	}
+
+	return false, fmt.Errorf("unknown operator %q", operator)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLookupJSONPath(t *testing.T) {
+	var document any
+	if err := json.Unmarshal([]byte(`{"maintenance":false,"version":"29.0.1","nodes":[{"name":"a","load":0.5},{"name":"b","load":2}]}`), &document); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want any
+		ok   bool
+	}{
+		{path: "maintenance", want: false, ok: true},
+		{path: "$.version", want: "29.0.1", ok: true},
+		{path: "nodes.1.name", want: "b", ok: true},
+		{path: "$.nodes.0.load", want: 0.5, ok: true},
+		{path: "$", want: document, ok: true},
+		{path: "missing"},
+		{path: "nodes.2.name"},
+		{path: "nodes.first"},
+		{path: "version.major"},
+	}
+
+	for _, test := range tests {
+		got, err := lookupJSONPath(document, test.path)
+		if test.ok != (err == nil) {
+			t.Errorf("lookupJSONPath(%q) error = %v", test.path, err)
+			continue
+		}
+		if test.ok && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("lookupJSONPath(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestCompareJSON(t *testing.T) {
+	tests := []struct {
+		actual   any
+		operator string
+		expected any
+		want     bool
+		err      bool
+	}{
+		{actual: false, operator: "==", expected: false, want: true},
+		{actual: true, operator: "==", expected: false, want: false},
+		{actual: "ok", operator: "!=", expected: "error", want: true},
+		{actual: 3.0, operator: "==", expected: 3.0, want: true},
+		{actual: 3.0, operator: "<", expected: 5.0, want: true},
+		{actual: 5.0, operator: "<=", expected: 5.0, want: true},
+		{actual: 5.0, operator: ">", expected: 5.0, want: false},
+		{actual: 5.0, operator: ">=", expected: 5.0, want: true},
+		{actual: "5", operator: ">", expected: 1.0, err: true},
+		{actual: 5.0, operator: "~", expected: 1.0, err: true},
+	}
+
+	for _, test := range tests {
+		got, err := compareJSON(test.actual, test.operator, test.expected)
+		if test.err != (err != nil) {
+			t.Errorf("compareJSON(%v %s %v) error = %v", test.actual, test.operator, test.expected, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("compareJSON(%v %s %v) = %t, want %t", test.actual, test.operator, test.expected, got, test.want)
+		}
+	}
+}
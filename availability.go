@@ -0,0 +1,79 @@
+package main
+
+import (
+	"time"
+)
+
+const availabilityDays = 90
+
+type AvailabilityWindow struct {
+	Name     string
+	Duration time.Duration
+}
+
+var availabilityWindows = []AvailabilityWindow{
+	{Name: "24h", Duration: 24 * time.Hour},
+	{Name: "7d", Duration: 7 * 24 * time.Hour},
+	{Name: "30d", Duration: 30 * 24 * time.Hour},
+	{Name: "90d", Duration: 90 * 24 * time.Hour},
+}
+
+type Availability struct {
+	Window  string
+	Percent float64
+	HasData bool
+}
+
+type DayAvailability struct {
+	Date    time.Time
+	Percent float64
+	HasData bool
+}
+
+func availability(name string) []Availability {
+	now := time.Now()
+	series := serviceSeries(name, "up")
+
+	windows := make([]Availability, 0, len(availabilityWindows))
+	for _, window := range availabilityWindows {
+		resolution := "hour"
+		if window.Duration <= 24*time.Hour {
+			resolution = "minute"
+		}
+
+		samples, _ := history.Since(series, resolution, now.Add(-window.Duration))
+		windows = append(windows, Availability{
+			Window:  window.Name,
+			Percent: samples.Average() * 100,
+			HasData: len(samples) > 0,
+		})
+	}
+
+	return windows
+}
+
+func dailyAvailability(name string) []DayAvailability {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -(availabilityDays - 1))
+
+	samples, _ := history.Since(serviceSeries(name, "up"), "hour", start)
+
+	totals := make([]Sample, availabilityDays)
+	for _, sample := range samples {
+		day := int(sample.Time.Sub(start) / (24 * time.Hour))
+		if day >= 0 && day < availabilityDays {
+			totals[day].merge(sample)
+		}
+	}
+
+	days := make([]DayAvailability, availabilityDays)
+	for i, total := range totals {
+		days[i] = DayAvailability{
+			Date:    start.AddDate(0, 0, i),
+			Percent: total.Value * 100,
+			HasData: total.Count > 0,
+		}
+	}
+
+	return days
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func useTestHistory(t *testing.T) {
+	t.Helper()
+
+	config.HistoryRawMinutes = defaultHistoryRawMinutes
+	config.HistoryMinuteHours = defaultHistoryMinuteHours
+	config.HistoryHourDays = defaultHistoryHourDays
+
+	previous := history
+	history = &History{series: make(map[string]*series)}
+	t.Cleanup(func() { history = previous })
+}
+
+func TestAvailability(t *testing.T) {
+	useTestHistory(t)
+
+	now := time.Now()
+	series := serviceSeries("NAS", "up")
+	history.Add(series, time.Hour, now.Add(-5*24*time.Hour), 0)
+	history.Add(series, time.Hour, now.Add(-3*time.Hour), 0)
+	history.Add(series, time.Hour, now.Add(-2*time.Hour), 1)
+	history.Add(series, time.Hour, now.Add(-time.Hour), 1)
+
+	want := map[string]float64{"24h": 200.0 / 3, "7d": 50, "30d": 50, "90d": 50}
+	for _, window := range availability("NAS") {
+		if !window.HasData {
+			t.Errorf("%s has no data", window.Window)
+			continue
+		}
+		if math.Abs(window.Percent-want[window.Window]) > 0.01 {
+			t.Errorf("%s = %.2f%%, want %.2f%%", window.Window, window.Percent, want[window.Window])
+		}
+	}
+
+	for _, window := range availability("Printer") {
+		if window.HasData {
+			t.Errorf("%s of a service without history has data", window.Window)
+		}
+	}
+}
+
+func TestDailyAvailability(t *testing.T) {
+	useTestHistory(t)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	series := serviceSeries("NAS", "up")
+	history.Add(series, time.Hour, today.AddDate(0, 0, -10).Add(12*time.Hour), 0)
+	history.Add(series, time.Hour, today.Add(-90*time.Minute), 1)
+	history.Add(series, time.Hour, today.Add(-30*time.Minute), 0)
+	history.Add(series, time.Hour, today.Add(30*time.Minute), 1)
+
+	days := dailyAvailability("NAS")
+	if len(days) != availabilityDays {
+		t.Fatalf("got %d days, want %d", len(days), availabilityDays)
+	}
+
+	last := days[availabilityDays-1]
+	if !last.Date.Equal(today) || last.Date.Location() != time.UTC {
+		t.Errorf("last day = %s, want %s", last.Date, today)
+	}
+	if first := days[0].Date; !first.Equal(today.AddDate(0, 0, -(availabilityDays - 1))) {
+		t.Errorf("first day = %s, want %s", first, today.AddDate(0, 0, -(availabilityDays-1)))
+	}
+
+	tests := []struct {
+		index   int
+		percent float64
+		hasData bool
+	}{
+		{index: availabilityDays - 1, percent: 100, hasData: true},
+		{index: availabilityDays - 2, percent: 50, hasData: true},
+		{index: availabilityDays - 11, percent: 0, hasData: true},
+		{index: availabilityDays - 3, hasData: false},
+		{index: 0, hasData: false},
+	}
+	for _, test := range tests {
+		day := days[test.index]
+		if day.HasData != test.hasData || math.Abs(day.Percent-test.percent) > 0.01 {
+			t.Errorf("%s = %.2f%% (data %t), want %.2f%% (data %t)",
+				day.Date.Format(time.DateOnly), day.Percent, day.HasData, test.percent, test.hasData)
+		}
+	}
+}
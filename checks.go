@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"time"
+)
+
+const (
+	defaultRefreshIntervalSeconds = 10
+	defaultTimeoutSeconds         = 5
+	defaultMaxConcurrentChecks    = 4
+	defaultDegradedCertDays       = 14
+	defaultRetryDelay             = 500 * time.Millisecond
+)
+
+func checkTimeout(check HealthCheck) time.Duration {
+	if check.Timeout > 0 {
+		return time.Duration(check.Timeout) * time.Second
+	}
+
+	return time.Duration(config.TimeoutSeconds) * time.Second
+}
+
+func degradedLatency(check HealthCheck) time.Duration {
+	if check.DegradedLatency > 0 {
+		return time.Duration(check.DegradedLatency) * time.Millisecond
+	}
+
+	return time.Duration(config.DegradedLatencyMs) * time.Millisecond
+}
+
+func certDays(check HealthCheck) int {
+	if check.DegradedCertDays > 0 {
+		return check.DegradedCertDays
+	}
+
+	return config.DegradedCertDays
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func checkInterval(check HealthCheck) time.Duration {
+	if check.Interval > 0 {
+		return time.Duration(check.Interval) * time.Second
+	}
+
+	return time.Duration(config.RefreshIntervalSeconds) * time.Second
+}
+
+func jitter(interval time.Duration) time.Duration {
+	if interval/10 <= 0 {
+		return 0
+	}
+
+	return rand.N(interval / 10)
+}
+
+var workers chan struct{}
+
+func scheduleHealthChecks() {
+	workers = make(chan struct{}, config.MaxConcurrentChecks)
+	for i, check := range config.HealthChecks {
+		check.id = fmt.Sprintf("config/%d", i)
+		addCheck(context.Background(), check)
+	}
+}
+
+func addCheck(ctx context.Context, check HealthCheck) {
+	check.Status = StatusUnknown
+	if check.Paused {
+		check.Status = StatusPaused
+	}
+
+	reportMutex.Lock()
+	healthchecks = append(healthchecks, check)
+	reportMutex.Unlock()
+
+	if !check.Paused {
+		go scheduleCheck(ctx, check)
+	}
+}
+
+func removeCheck(id string) {
+	reportMutex.Lock()
+	healthchecks = slices.DeleteFunc(healthchecks, func(check HealthCheck) bool {
+		return check.id == id
+	})
+	reportMutex.Unlock()
+}
+
+func storeResult(result HealthCheck) {
+	reportMutex.Lock()
+	defer reportMutex.Unlock()
+
+	for i := range healthchecks {
+		if healthchecks[i].id == result.id {
+			healthchecks[i] = result
+			return
+		}
+	}
+}
+
+func scheduleCheck(ctx context.Context, check HealthCheck) {
+	interval := checkInterval(check)
+	timer := time.NewTimer(jitter(interval))
+	defer timer.Stop()
+
+	var tlsReady bool
+	previous := check
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		var result HealthCheck
+		if !tlsReady {
+			if err := setupTLS(&check); err != nil {
+				log.Printf("Error setting up TLS for %s: %v", check.Name, err)
+
+				result = check
+				result.Status = StatusDown
+				result.Message = err.Error()
+				result.CheckedAt = time.Now()
+			} else {
+				tlsReady = true
+			}
+		}
+		if tlsReady {
+			result = runWithRetries(ctx, check)
+		}
+
+		result = applyThresholds(previous, result)
+		if result.Status != previous.Status && previous.Status != StatusUnknown {
+			log.Printf("%s is now %s", result.Name, result.Status)
+		}
+
+		storeResult(result)
+		recordResult(result)
+		previous = result
+		timer.Reset(interval + jitter(interval))
+	}
+}
+
+func runWithRetries(ctx context.Context, check HealthCheck) HealthCheck {
+	delay := time.Duration(check.RetryDelay) * time.Millisecond
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	for attempt := 0; ; attempt++ {
+		workers <- struct{}{}
+		result := runCheck(check)
+		<-workers
+
+		if result.Status != StatusDown || attempt >= check.Retries {
+			return result
+		}
+
+		select {
+		case <-ctx.Done():
+			return result
+		case <-time.After(delay << attempt):
+		}
+	}
+}
+
+func applyThresholds(previous, result HealthCheck) HealthCheck {
+	failureThreshold := max(result.FailureThreshold, 1)
+	successThreshold := max(result.SuccessThreshold, 1)
+
+	if result.Status == StatusDown {
+		result.Failures = previous.Failures + 1
+		result.Successes = 0
+
+		if previous.Healthy() && result.Failures < failureThreshold {
+			result.Message = fmt.Sprintf("Failed %d of %d times: %s", result.Failures, failureThreshold, result.Message)
+			result.Status = previous.Status
+			result.TimedOut = false
+		}
+	} else {
+		result.Successes = previous.Successes + 1
+		result.Failures = 0
+
+		if previous.Status == StatusDown && result.Successes < successThreshold {
+			result.Message = fmt.Sprintf("Recovering, passed %d of %d times", result.Successes, successThreshold)
+			result.Status = StatusDown
+		}
+	}
+
+	return result
+}
+
+func runCheck(check HealthCheck) HealthCheck {
+	check.Status = StatusUp
+	check.TimedOut = false
+	check.Message = ""
+	check.Response = 0
+	check.Latency = 0
+	check.Timing = Timing{}
+	check.Ping = PingStats{}
+	check.Unit = UnitState{}
+	check.Container = ContainerState{}
+	check.Processes = ProcessStats{}
+	check.File = FileStats{}
+	check.CertExpiry = time.Time{}
+	check.Certificates = nil
+
+	timeout := checkTimeout(check)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var err error
+	start := time.Now()
+	switch check.Type {
+	case "", "http":
+		err = checkHTTP(ctx, &check)
+	case "tcp":
+		err = checkTCP(ctx, &check)
+	case "tls":
+		err = checkTLS(ctx, &check)
+	case "dns":
+		err = checkDNS(ctx, &check)
+	case "ping":
+		err = checkPing(ctx, &check)
+	case "exec":
+		err = checkExec(ctx, &check)
+	case "systemd":
+		err = checkSystemd(ctx, &check)
+	case "docker":
+		err = checkDocker(ctx, &check)
+	case "process":
+		err = checkProcess(ctx, &check)
+	case "file":
+		err = checkFile(ctx, &check)
+	default:
+		err = fmt.Errorf("unknown check type %q", check.Type)
+	}
+	if check.Latency == 0 {
+		check.Latency = time.Since(start)
+	}
+	check.CheckedAt = time.Now()
+
+	if threshold := degradedLatency(check); err == nil && check.Status == StatusUp && threshold > 0 && check.Latency > threshold {
+		check.Status = StatusDegraded
+		check.Message = fmt.Sprintf("Slow response, over %s", threshold)
+	}
+
+	if err != nil {
+		check.Status = StatusDown
+		if isTimeout(err) {
+			check.TimedOut = true
+			check.Message = fmt.Sprintf("Timed out after %s", timeout)
+			log.Printf("Health check for %s timed out after %s", check.Name, timeout)
+		} else {
+			check.Message = err.Error()
+			log.Printf("Error checking health of %s: %v", check.Name, err)
+		}
+	}
+
+	return check
+}
+
+func checkTCP(ctx context.Context, check *HealthCheck) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", check.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
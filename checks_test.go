@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyThresholds(t *testing.T) {
+	runs := []struct {
+		status  Status
+		want    Status
+		message string
+	}{
+		{status: StatusUp, want: StatusUp},
+		{status: StatusDown, want: StatusUp, message: "Failed 1 of 3 times"},
+		{status: StatusDown, want: StatusUp, message: "Failed 2 of 3 times"},
+		{status: StatusDown, want: StatusDown},
+		{status: StatusDown, want: StatusDown},
+		{status: StatusUp, want: StatusDown, message: "Recovering, passed 1 of 2 times"},
+		{status: StatusDegraded, want: StatusDegraded},
+		{status: StatusDown, want: StatusDegraded, message: "Failed 1 of 3 times"},
+		{status: StatusUp, want: StatusUp},
+	}
+
+	previous := HealthCheck{Status: StatusUnknown, FailureThreshold: 3, SuccessThreshold: 2}
+	for i, run := range runs {
+		result := previous
+		result.Status = run.status
+		result.Message = ""
+
+		result = applyThresholds(previous, result)
+		if result.Status != run.want {
+			t.Fatalf("run %d: status %s, want %s", i, result.Status, run.want)
+		}
+		if !strings.HasPrefix(result.Message, run.message) {
+			t.Fatalf("run %d: message %q, want prefix %q", i, result.Message, run.message)
+		}
+		previous = result
+	}
+}
+
+func TestApplyThresholdsDefaults(t *testing.T) {
+	previous := HealthCheck{Status: StatusUp}
+
+	result := applyThresholds(previous, HealthCheck{Status: StatusDown})
+	if result.Status != StatusDown || result.Failures != 1 {
+		t.Errorf("first failure = %s after %d failures, want down after 1", result.Status, result.Failures)
+	}
+
+	result = applyThresholds(result, HealthCheck{Status: StatusUp})
+	if result.Status != StatusUp || result.Successes != 1 || result.Failures != 0 {
+		t.Errorf("first success = %s after %d successes and %d failures, want up", result.Status, result.Successes, result.Failures)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDiscoveryInterval = 30
+	labelPrefix              = "status."
+)
+
+type dockerListedContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// labelIcon renders an icon label as an image. Labels can come from any image,
+// so unlike config icons they are never trusted as markup.
+func labelIcon(value string) template.HTML {
+	if value == "" {
+		return ""
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "") {
+		log.Printf("Ignoring %sicon label %q: not an http, https or relative URL", labelPrefix, value)
+		return ""
+	}
+
+	return template.HTML(`<img src="` + template.HTMLEscapeString(value) + `" alt="">`)
+}
+
+func checkFromLabels(container dockerListedContainer) (HealthCheck, bool) {
+	labels := container.Labels
+	if labels[labelPrefix+"enable"] != "true" && labels[labelPrefix+"name"] == "" {
+		return HealthCheck{}, false
+	}
+
+	name := container.ID
+	if len(container.Names) > 0 {
+		name = strings.TrimPrefix(container.Names[0], "/")
+	}
+
+	check := HealthCheck{
+		id:          "docker/" + container.ID,
+		Name:        labels[labelPrefix+"name"],
+		Description: labels[labelPrefix+"description"],
+		Icon:        labelIcon(labels[labelPrefix+"icon"]),
+		Type:        labels[labelPrefix+"type"],
+		Endpoint:    labels[labelPrefix+"endpoint"],
+	}
+	if check.Name == "" {
+		check.Name = name
+	}
+	if check.Type == "" && check.Endpoint == "" {
+		check.Type = "docker"
+	}
+	if check.Type == "docker" && check.Endpoint == "" {
+		check.Endpoint = container.ID
+	}
+
+	if value := labels[labelPrefix+"status_code"]; value != "" {
+		if err := json.Unmarshal(strconv.AppendQuote(nil, value), &check.StatusCode); err != nil {
+			log.Printf("Ignoring %sstatus_code label on %s: %v", labelPrefix, name, err)
+		}
+	}
+	if value := labels[labelPrefix+"interval_seconds"]; value != "" {
+		check.Interval, _ = strconv.Atoi(value)
+	}
+
+	return check, true
+}
+
+func discoverContainers() {
+	running := make(map[string]context.CancelFunc)
+
+	static := make(map[string]bool, len(config.HealthChecks))
+	for _, check := range config.HealthChecks {
+		static[check.Name] = true
+	}
+
+	for {
+		var containers []dockerListedContainer
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.TimeoutSeconds)*time.Second)
+		err := dockerGet(ctx, "/containers/json?all=true", &containers)
+		cancel()
+
+		if err != nil {
+			log.Printf("Error discovering containers: %v", err)
+		} else {
+			seen := make(map[string]bool, len(containers))
+			for _, container := range containers {
+				check, ok := checkFromLabels(container)
+				if !ok || static[check.Name] {
+					continue
+				}
+
+				seen[check.id] = true
+				if _, ok := running[check.id]; ok {
+					continue
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				running[check.id] = cancel
+				addCheck(ctx, check)
+				log.Printf("Discovered %s from container labels", check.Name)
+			}
+
+			for id, cancel := range running {
+				if !seen[id] {
+					cancel()
+					removeCheck(id)
+					delete(running, id)
+					log.Printf("Removed discovered check %s", id)
+				}
+			}
+		}
+
+		time.Sleep(time.Duration(config.DiscoveryInterval) * time.Second)
+	}
+}
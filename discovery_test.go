@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCheckFromLabels(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"Id":"aaa","Names":["/immich"],"Labels":{"status.name":"Immich","status.description":"Photos","status.endpoint":"http://immich:2283/api/server/ping","status.status_code":"200-299,301","status.interval_seconds":"60"}},
+			{"Id":"bbb","Names":["/postgres"],"Labels":{"status.enable":"true"}},
+			{"Id":"ccc","Names":["/redis"],"Labels":{"com.docker.compose.service":"redis"}},
+			{"Id":"ddd","Names":["/evil"],"Labels":{"status.name":"Evil","status.icon":"<script>alert(1)</script>"}},
+			{"Id":"eee","Names":["/logo"],"Labels":{"status.name":"Logo","status.icon":"https://example.com/logo.svg?a=1&b=\"2\""}},
+			{"Id":"fff","Names":["/js"],"Labels":{"status.name":"JS","status.icon":"javascript:alert(1)"}}
+		]`))
+	})
+	serveDocker(t, mux)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var containers []dockerListedContainer
+	if err := dockerGet(ctx, "/containers/json?all=true", &containers); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := make(map[string]HealthCheck)
+	for _, container := range containers {
+		if check, ok := checkFromLabels(container); ok {
+			checks[container.ID] = check
+		}
+	}
+
+	if _, ok := checks["ccc"]; ok {
+		t.Error("container without status labels was discovered")
+	}
+
+	immich := checks["aaa"]
+	if immich.id != "docker/aaa" || immich.Name != "Immich" || immich.Description != "Photos" {
+		t.Errorf("immich = %q %q %q", immich.id, immich.Name, immich.Description)
+	}
+	if immich.Type != "" || immich.Endpoint != "http://immich:2283/api/server/ping" {
+		t.Errorf("immich type %q endpoint %q, want an http check", immich.Type, immich.Endpoint)
+	}
+	if !immich.StatusCode.Contains(301) || immich.StatusCode.Contains(302) {
+		t.Errorf("immich status codes = %v", immich.StatusCode)
+	}
+	if immich.Interval != 60 {
+		t.Errorf("immich interval = %d, want 60", immich.Interval)
+	}
+
+	postgres := checks["bbb"]
+	if postgres.Name != "postgres" || postgres.Type != "docker" || postgres.Endpoint != "bbb" {
+		t.Errorf("postgres = %q %q %q, want a docker check of the container", postgres.Name, postgres.Type, postgres.Endpoint)
+	}
+
+	icons := map[string]string{
+		"ddd": `<img src="&lt;script&gt;alert(1)&lt;/script&gt;" alt="">`,
+		"eee": `<img src="https://example.com/logo.svg?a=1&amp;b=&#34;2&#34;" alt="">`,
+		"fff": ``,
+	}
+	for id, want := range icons {
+		if got := string(checks[id].Icon); got != want {
+			t.Errorf("icon of %s = %s, want %s", id, got, want)
+		}
+	}
+}
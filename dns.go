@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var recordTypes = map[string]dnsmessage.Type{
+	"":      dnsmessage.TypeA,
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"NS":    dnsmessage.TypeNS,
+	"TXT":   dnsmessage.TypeTXT,
+}
+
+func newQuery(name string, recordType dnsmessage.Type) (uint16, []byte, error) {
+	fqdn, err := dnsmessage.NewName(strings.TrimSuffix(name, ".") + ".")
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid query %q: %w", name, err)
+	}
+
+	id := uint16(rand.N(1 << 16))
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: fqdn, Type: recordType, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+
+	return id, packed, err
+}
+
+func exchangeUDP(conn net.Conn, id uint16, query []byte) (*dnsmessage.Message, error) {
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	buffer := make([]byte, 512)
+	for {
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return nil, err
+		}
+
+		var response dnsmessage.Message
+		if err := response.Unpack(buffer[:n]); err != nil || response.ID != id || !response.Response {
+			continue
+		}
+
+		return &response, nil
+	}
+}
+
+func exchangeTCP(conn net.Conn, id uint16, query []byte) (*dnsmessage.Message, error) {
+	if _, err := conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(query)))); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	buffer := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, buffer); err != nil {
+		return nil, err
+	}
+
+	var response dnsmessage.Message
+	if err := response.Unpack(buffer); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	if response.ID != id {
+		return nil, errors.New("response does not match query")
+	}
+
+	return &response, nil
+}
+
+// exchange sends the query straight to server, so unlike the system resolver
+// it never answers from /etc/hosts or tries search domains.
+func exchange(ctx context.Context, server, network string, id uint16, query []byte) (*dnsmessage.Message, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		return exchangeTCP(conn, id, query)
+	}
+
+	return exchangeUDP(conn, id, query)
+}
+
+func answerValue(answer dnsmessage.Resource) string {
+	switch body := answer.Body.(type) {
+	case *dnsmessage.AResource:
+		return net.IP(body.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		return net.IP(body.AAAA[:]).String()
+	case *dnsmessage.CNAMEResource:
+		return body.CNAME.String()
+	case *dnsmessage.MXResource:
+		return body.MX.String()
+	case *dnsmessage.NSResource:
+		return body.NS.String()
+	case *dnsmessage.TXTResource:
+		return strings.Join(body.TXT, "")
+	}
+
+	return ""
+}
+
+func lookup(ctx context.Context, server, recordType, name string) ([]string, error) {
+	queryType, ok := recordTypes[strings.ToUpper(recordType)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+
+	id, query, err := newQuery(name, queryType)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := exchange(ctx, server, "udp", id, query)
+	if err == nil && response.Truncated {
+		response, err = exchange(ctx, server, "tcp", id, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, fmt.Errorf("lookup %s: no such host", name)
+	default:
+		return nil, fmt.Errorf("lookup %s: server returned %s", name, strings.TrimPrefix(response.RCode.String(), "RCode"))
+	}
+
+	var answers []string
+	for _, answer := range response.Answers {
+		if answer.Header.Type == queryType {
+			answers = append(answers, answerValue(answer))
+		}
+	}
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("lookup %s: no %s records", name, strings.TrimPrefix(queryType.String(), "Type"))
+	}
+
+	return answers, nil
+}
+
+func checkDNS(ctx context.Context, check *HealthCheck) error {
+	server := check.Endpoint
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	answers, err := lookup(ctx, server, check.RecordType, check.Query)
+	if err != nil {
+		return err
+	}
+
+	for _, expected := range check.Expected {
+		if !slices.ContainsFunc(answers, func(answer string) bool {
+			return strings.EqualFold(strings.TrimSuffix(answer, "."), strings.TrimSuffix(expected, "."))
+		}) {
+			return fmt.Errorf("expected %s in answer, got %s", expected, strings.Join(answers, ", "))
+		}
+	}
+
+	return nil
+}
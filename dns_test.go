@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// serveDNS answers A queries from records on a local UDP socket and returns
+// NXDOMAIN for any other name.
+func serveDNS(t *testing.T, records map[string][4]byte) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+
+			var request dnsmessage.Message
+			if err := request.Unpack(buffer[:n]); err != nil || len(request.Questions) == 0 {
+				continue
+			}
+			question := request.Questions[0]
+
+			response := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: request.ID, Response: true, Authoritative: true},
+				Questions: request.Questions,
+			}
+			ip, ok := records[question.Name.String()]
+			switch {
+			case !ok:
+				response.RCode = dnsmessage.RCodeNameError
+			case question.Type == dnsmessage.TypeA:
+				response.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.AResource{A: ip},
+				}}
+			}
+
+			packed, err := response.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestCheckDNS(t *testing.T) {
+	server := serveDNS(t, map[string][4]byte{
+		"nas.home.": {192, 168, 1, 10},
+	})
+
+	tests := []struct {
+		name  string
+		check HealthCheck
+		err   string
+	}{
+		{
+			name:  "resolves",
+			check: HealthCheck{Query: "nas.home", RecordType: "A"},
+		},
+		{
+			name:  "expected answer",
+			check: HealthCheck{Query: "nas.home", Expected: []string{"192.168.1.10"}},
+		},
+		{
+			name:  "unexpected answer",
+			check: HealthCheck{Query: "nas.home", Expected: []string{"192.168.1.11"}},
+			err:   "expected 192.168.1.11 in answer, got 192.168.1.10",
+		},
+		{
+			name:  "missing name",
+			check: HealthCheck{Query: "printer.home"},
+			err:   "no such host",
+		},
+		{
+			name:  "resolver down",
+			check: HealthCheck{Endpoint: "127.0.0.1:1", Query: "localhost"},
+			err:   "connection refused",
+		},
+		{
+			name:  "no records of type",
+			check: HealthCheck{Query: "nas.home", RecordType: "AAAA"},
+			err:   "no AAAA records",
+		},
+		{
+			name:  "unsupported record type",
+			check: HealthCheck{Query: "nas.home", RecordType: "SRV"},
+			err:   `unsupported record type "SRV"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			check := test.check
+			if check.Endpoint == "" {
+				check.Endpoint = server
+			}
+			err := checkDNS(ctx, &check)
+
+			if test.err == "" && err != nil {
+				t.Fatalf("checkDNS() = %v, want nil", err)
+			}
+			if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Fatalf("checkDNS() = %v, want error containing %q", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultDockerSocket = "/var/run/docker.sock"
+
+var dockerClient *http.Client
+
+type ContainerState struct {
+	State     string
+	Health    string
+	Restarts  int
+	StartedAt time.Time
+}
+
+type dockerContainer struct {
+	Name         string `json:"Name"`
+	RestartCount int    `json:"RestartCount"`
+	State        struct {
+		Status    string    `json:"Status"`
+		Running   bool      `json:"Running"`
+		ExitCode  int       `json:"ExitCode"`
+		StartedAt time.Time `json:"StartedAt"`
+		Health    *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+}
+
+func newDockerClient(socket string) *http.Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+func dockerGet(ctx context.Context, path string, v any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := dockerClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("not found")
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("docker API returned %s", response.Status)
+	}
+
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+func checkDocker(ctx context.Context, check *HealthCheck) error {
+	var container dockerContainer
+	if err := dockerGet(ctx, "/containers/"+url.PathEscape(check.Endpoint)+"/json", &container); err != nil {
+		return fmt.Errorf("container %s: %w", check.Endpoint, err)
+	}
+
+	check.Container = ContainerState{
+		State:     container.State.Status,
+		Restarts:  container.RestartCount,
+		StartedAt: container.State.StartedAt,
+	}
+	if container.State.Health != nil {
+		check.Container.Health = container.State.Health.Status
+	}
+
+	if !container.State.Running {
+		return fmt.Errorf("%s (exit code %d)", container.State.Status, container.State.ExitCode)
+	}
+
+	switch check.Container.Health {
+	case "unhealthy":
+		return fmt.Errorf("container %s is unhealthy", strings.TrimPrefix(container.Name, "/"))
+	case "starting":
+		check.Status = StatusDegraded
+		check.Message = "Health check starting"
+	}
+
+	if container.State.Status == "restarting" {
+		check.Status = StatusDegraded
+		check.Message = "Restarting"
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveDocker starts a fake Docker Engine API on a unix socket and points
+// dockerClient at it for the duration of the test.
+func serveDocker(t *testing.T, handler http.Handler) {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	previous := dockerClient
+	dockerClient = newDockerClient(socket)
+	t.Cleanup(func() { dockerClient = previous })
+}
+
+func TestCheckDocker(t *testing.T) {
+	containers := map[string]string{
+		"healthy":    `{"Name":"/healthy","RestartCount":2,"State":{"Status":"running","Running":true,"StartedAt":"2025-01-01T12:00:00Z","Health":{"Status":"healthy"}}}`,
+		"plain":      `{"Name":"/plain","State":{"Status":"running","Running":true}}`,
+		"starting":   `{"Name":"/starting","State":{"Status":"running","Running":true,"Health":{"Status":"starting"}}}`,
+		"unhealthy":  `{"Name":"/unhealthy","State":{"Status":"running","Running":true,"Health":{"Status":"unhealthy"}}}`,
+		"restarting": `{"Name":"/restarting","RestartCount":5,"State":{"Status":"restarting","Running":true}}`,
+		"exited":     `{"Name":"/exited","State":{"Status":"exited","Running":false,"ExitCode":137}}`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := containers[r.PathValue("id")]
+		if !ok {
+			http.Error(w, `{"message":"No such container"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+	serveDocker(t, mux)
+
+	tests := []struct {
+		endpoint string
+		status   Status
+		health   string
+		restarts int
+		err      string
+	}{
+		{endpoint: "healthy", status: StatusUp, health: "healthy", restarts: 2},
+		{endpoint: "plain", status: StatusUp},
+		{endpoint: "starting", status: StatusDegraded, health: "starting"},
+		{endpoint: "unhealthy", health: "unhealthy", err: "container unhealthy is unhealthy"},
+		{endpoint: "restarting", status: StatusDegraded, restarts: 5},
+		{endpoint: "exited", err: "exited (exit code 137)"},
+		{endpoint: "missing", err: "container missing: not found"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.endpoint, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			check := HealthCheck{Endpoint: test.endpoint, Status: StatusUp}
+			err := checkDocker(ctx, &check)
+
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("checkDocker() = %v, want error containing %q", err, test.err)
+				}
+			} else if err != nil {
+				t.Fatalf("checkDocker() = %v, want nil", err)
+			} else if check.Status != test.status {
+				t.Errorf("Status = %s, want %s", check.Status, test.status)
+			}
+
+			if check.Container.Health != test.health {
+				t.Errorf("Health = %q, want %q", check.Container.Health, test.health)
+			}
+			if check.Container.Restarts != test.restarts {
+				t.Errorf("Restarts = %d, want %d", check.Container.Restarts, test.restarts)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+func firstLine(output []byte) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line)
+}
+
+func checkExec(ctx context.Context, check *HealthCheck) error {
+	if len(check.Command) == 0 {
+		return errors.New("no command configured")
+	}
+
+	command := exec.CommandContext(ctx, check.Command[0], check.Command[1:]...)
+	command.Dir = check.WorkingDir
+	command.Env = os.Environ()
+	for key, value := range check.Env {
+		command.Env = append(command.Env, key+"="+value)
+	}
+	command.WaitDelay = time.Second
+
+	var stdout bytes.Buffer
+	command.Stdout = &stdout
+
+	err := command.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	message := firstLine(stdout.Bytes())
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return err
+	}
+
+	switch command.ProcessState.ExitCode() {
+	case 0:
+		check.Message = message
+	case 1:
+		check.Status = StatusDegraded
+		check.Message = message
+	case 2:
+		if message == "" {
+			return errors.New("exited with status 2")
+		}
+		return errors.New(message)
+	default:
+		if message == "" {
+			return fmt.Errorf("exited with status %d", command.ProcessState.ExitCode())
+		}
+		return fmt.Errorf("exited with status %d: %s", command.ProcessState.ExitCode(), message)
+	}
+
+	return nil
+}
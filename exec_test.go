@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckExec(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		script  string
+		status  Status
+		message string
+		err     string
+	}{
+		{name: "ok", script: "echo 'ONLINE pool healthy'; echo second line", status: StatusUp, message: "ONLINE pool healthy"},
+		{name: "warning", script: "printf '\\n  DEGRADED one disk missing  \\n'; exit 1", status: StatusDegraded, message: "DEGRADED one disk missing"},
+		{name: "critical", script: "echo 'FAULTED'; exit 2", err: "FAULTED"},
+		{name: "critical without output", script: "exit 2", err: "exited with status 2"},
+		{name: "unknown", script: "echo 'no such pool'; exit 3", err: "exited with status 3: no such pool"},
+		{name: "environment and working directory", script: `echo "$POOL in $PWD"`, status: StatusUp, message: "tank in " + dir},
+		{name: "stderr ignored", script: "echo oops >&2", status: StatusUp},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			check := HealthCheck{
+				Command:    []string{"sh", "-c", test.script},
+				Env:        map[string]string{"POOL": "tank"},
+				WorkingDir: dir,
+				Status:     StatusUp,
+			}
+			err := checkExec(ctx, &check)
+
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("checkExec() = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkExec() = %v, want nil", err)
+			}
+			if check.Status != test.status || check.Message != test.message {
+				t.Errorf("got %s %q, want %s %q", check.Status, check.Message, test.status, test.message)
+			}
+		})
+	}
+}
+
+func TestCheckExecTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	check := HealthCheck{Command: []string{"sh", "-c", "sleep 30"}}
+
+	start := time.Now()
+	err := checkExec(ctx, &check)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("checkExec() = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("checkExec() returned after %s, want the command killed at the timeout", elapsed)
+	}
+}
+
+func TestCheckExecNoCommand(t *testing.T) {
+	check := HealthCheck{}
+	if err := checkExec(context.Background(), &check); err == nil {
+		t.Error("checkExec() without a command = nil, want an error")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type FileStats struct {
+	Count    int
+	Newest   string
+	Modified time.Time
+	Size     uint64
+	Dir      bool
+}
+
+func (stats FileStats) Age() time.Duration {
+	return time.Since(stats.Modified).Round(time.Minute)
+}
+
+func checkFile(ctx context.Context, check *HealthCheck) error {
+	paths, err := filepath.Glob(check.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		check.File.Count++
+		if info.ModTime().After(check.File.Modified) {
+			check.File.Newest = path
+			check.File.Modified = info.ModTime()
+			check.File.Dir = info.IsDir()
+			check.File.Size = 0
+			if !info.IsDir() {
+				check.File.Size = uint64(info.Size())
+			}
+		}
+	}
+
+	if check.File.Count == 0 {
+		return fmt.Errorf("nothing matches %s", check.Endpoint)
+	}
+
+	if check.MaxAgeHours > 0 && check.File.Age() > time.Duration(check.MaxAgeHours)*time.Hour {
+		return fmt.Errorf("%s is %s old", filepath.Base(check.File.Newest), check.File.Age())
+	}
+
+	if !check.File.Dir && check.File.Size < check.MinSizeBytes {
+		return fmt.Errorf("%s is %s, expected at least %s", filepath.Base(check.File.Newest), formatBytes(check.File.Size), formatBytes(check.MinSizeBytes))
+	}
+
+	return ctx.Err()
+}
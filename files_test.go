@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, size int, modified time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	writeFile(t, filepath.Join(dir, "backup-1.tar"), 4096, now.Add(-50*time.Hour))
+	writeFile(t, filepath.Join(dir, "backup-2.tar"), 2048, now.Add(-2*time.Hour))
+	writeFile(t, filepath.Join(dir, "notes.txt"), 10, now.Add(-time.Minute))
+
+	snapshots := filepath.Join(dir, "snapshots")
+	if err := os.Mkdir(snapshots, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(snapshots, now.Add(-3*time.Hour), now.Add(-3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		check  HealthCheck
+		count  int
+		newest string
+		size   uint64
+		dir    bool
+		err    string
+	}{
+		{
+			name:   "glob picks newest",
+			check:  HealthCheck{Endpoint: filepath.Join(dir, "backup-*.tar")},
+			count:  2,
+			newest: "backup-2.tar",
+			size:   2048,
+		},
+		{
+			name:   "fresh enough",
+			check:  HealthCheck{Endpoint: filepath.Join(dir, "backup-*.tar"), MaxAgeHours: 26},
+			count:  2,
+			newest: "backup-2.tar",
+			size:   2048,
+		},
+		{
+			name:  "too old",
+			check: HealthCheck{Endpoint: filepath.Join(dir, "backup-1.tar"), MaxAgeHours: 26},
+			err:   "backup-1.tar is 50h0m0s old",
+		},
+		{
+			name:   "large enough",
+			check:  HealthCheck{Endpoint: filepath.Join(dir, "backup-2.tar"), MinSizeBytes: 2048},
+			count:  1,
+			newest: "backup-2.tar",
+			size:   2048,
+		},
+		{
+			name:  "too small",
+			check: HealthCheck{Endpoint: filepath.Join(dir, "backup-2.tar"), MinSizeBytes: 4096},
+			err:   "backup-2.tar is 2.00 KB, expected at least 4.00 KB",
+		},
+		{
+			name:   "directory",
+			check:  HealthCheck{Endpoint: snapshots, MaxAgeHours: 26, MinSizeBytes: 1 << 20},
+			count:  1,
+			newest: "snapshots",
+			dir:    true,
+		},
+		{
+			name:   "newest of files and directories",
+			check:  HealthCheck{Endpoint: filepath.Join(dir, "*")},
+			count:  4,
+			newest: "notes.txt",
+			size:   10,
+		},
+		{
+			name:  "nothing matches",
+			check: HealthCheck{Endpoint: filepath.Join(dir, "*.zip")},
+			err:   "nothing matches",
+		},
+		{
+			name:  "bad pattern",
+			check: HealthCheck{Endpoint: filepath.Join(dir, "[")},
+			err:   "syntax error in pattern",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			check := test.check
+			err := checkFile(context.Background(), &check)
+
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("checkFile() = %v, want error containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkFile() = %v, want nil", err)
+			}
+
+			stats := check.File
+			if stats.Count != test.count || filepath.Base(stats.Newest) != test.newest || stats.Size != test.size || stats.Dir != test.dir {
+				t.Errorf("got %d matches, newest %s (%d bytes, dir %t), want %d, %s (%d bytes, dir %t)",
+					stats.Count, filepath.Base(stats.Newest), stats.Size, stats.Dir, test.count, test.newest, test.size, test.dir)
+			}
+		})
+	}
+}
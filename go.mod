@@ -2,7 +2,11 @@ module status
 
 go 1.25
 
-require github.com/shirou/gopsutil/v4 v4.25.10
+require (
+	github.com/godbus/dbus/v5 v5.1.0
+	github.com/shirou/gopsutil/v4 v4.25.10
+	golang.org/x/net v0.46.0
+)
 
 require (
 	github.com/ebitengine/purego v0.9.0 // indirect
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHistoryRawMinutes  = 60
+	defaultHistoryMinuteHours = 24
+	defaultHistoryHourDays    = 90
+)
+
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Count int       `json:"count"`
+}
+
+func (sample *Sample) add(value float64) {
+	if sample.Count == 0 || value < sample.Min {
+		sample.Min = value
+	}
+	if sample.Count == 0 || value > sample.Max {
+		sample.Max = value
+	}
+	sample.Value = (sample.Value*float64(sample.Count) + value) / float64(sample.Count+1)
+	sample.Count++
+}
+
+func (sample *Sample) merge(other Sample) {
+	if other.Count == 0 {
+		return
+	}
+	if sample.Count == 0 || other.Min < sample.Min {
+		sample.Min = other.Min
+	}
+	if sample.Count == 0 || other.Max > sample.Max {
+		sample.Max = other.Max
+	}
+	total := sample.Count + other.Count
+	sample.Value = (sample.Value*float64(sample.Count) + other.Value*float64(other.Count)) / float64(total)
+	sample.Count = total
+}
+
+type Samples []Sample
+
+func (samples Samples) Average() float64 {
+	var total Sample
+	for _, sample := range samples {
+		total.merge(sample)
+	}
+
+	return total.Value
+}
+
+func (samples Samples) Peak() float64 {
+	var peak float64
+	for _, sample := range samples {
+		peak = max(peak, sample.Max)
+	}
+
+	return peak
+}
+
+type ring struct {
+	samples []Sample
+	next    int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{samples: make([]Sample, 0, max(capacity, 1))}
+}
+
+func (r *ring) push(sample Sample) {
+	if len(r.samples) < cap(r.samples) {
+		r.samples = append(r.samples, sample)
+		return
+	}
+
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % len(r.samples)
+}
+
+func (r *ring) since(start time.Time) Samples {
+	var samples Samples
+	for i := range r.samples {
+		sample := r.samples[(r.next+i)%len(r.samples)]
+		if !sample.Time.Before(start) {
+			samples = append(samples, sample)
+		}
+	}
+
+	return samples
+}
+
+type series struct {
+	interval time.Duration
+	raw      *ring
+	minute   *ring
+	hour     *ring
+
+	currentMinute Sample
+	currentHour   Sample
+}
+
+func newSeries(interval time.Duration) *series {
+	interval = max(interval, time.Second)
+
+	return &series{
+		interval: interval,
+		raw:      newRing(int(time.Duration(config.HistoryRawMinutes) * time.Minute / interval)),
+		minute:   newRing(config.HistoryMinuteHours * 60),
+		hour:     newRing(config.HistoryHourDays * 24),
+	}
+}
+
+func (s *series) add(t time.Time, value float64) {
+	s.raw.push(Sample{Time: t, Value: value, Min: value, Max: value, Count: 1})
+
+	minute := t.Truncate(time.Minute)
+	if !s.currentMinute.Time.Equal(minute) {
+		if s.currentMinute.Count > 0 {
+			s.minute.push(s.currentMinute)
+		}
+		s.currentMinute = Sample{Time: minute}
+	}
+	s.currentMinute.add(value)
+
+	hour := t.Truncate(time.Hour)
+	if !s.currentHour.Time.Equal(hour) {
+		if s.currentHour.Count > 0 {
+			s.hour.push(s.currentHour)
+		}
+		s.currentHour = Sample{Time: hour}
+	}
+	s.currentHour.add(value)
+}
+
+func (s *series) since(resolution string, start time.Time) (Samples, error) {
+	switch resolution {
+	case "", "raw":
+		return s.raw.since(start), nil
+	case "minute":
+		samples := s.minute.since(start)
+		if s.currentMinute.Count > 0 && !s.currentMinute.Time.Before(start) {
+			samples = append(samples, s.currentMinute)
+		}
+		return samples, nil
+	case "hour":
+		samples := s.hour.since(start)
+		if s.currentHour.Count > 0 && !s.currentHour.Time.Before(start) {
+			samples = append(samples, s.currentHour)
+		}
+		return samples, nil
+	}
+
+	return nil, fmt.Errorf("unknown resolution %q", resolution)
+}
+
+type History struct {
+	mutex      sync.RWMutex
+	series     map[string]*series
+	log        *os.File
+	generation int
+}
+
+var history = &History{series: make(map[string]*series)}
+
+func (h *History) Add(name string, interval time.Duration, t time.Time, value float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.add(name, interval, t, value)
+	if h.log != nil {
+		h.appendLog(logEntry{Series: name, Interval: interval, Time: t, Value: value})
+	}
+}
+
+func (h *History) add(name string, interval time.Duration, t time.Time, value float64) {
+	s, ok := h.series[name]
+	if !ok {
+		s = newSeries(interval)
+		h.series[name] = s
+	}
+	s.add(t, value)
+}
+
+func (h *History) Since(name, resolution string, start time.Time) (Samples, error) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	s, ok := h.series[name]
+	if !ok {
+		return nil, nil
+	}
+
+	return s.since(resolution, start)
+}
+
+func (h *History) Names() []string {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	names := make([]string, 0, len(h.series))
+	for name := range h.series {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func serviceSeries(name, metric string) string {
+	return "services/" + name + "/" + metric
+}
+
+func recordStats(stats SystemStats) {
+	interval := time.Duration(config.RefreshIntervalSeconds) * time.Second
+
+	if len(stats.CPU) > 0 {
+		history.Add("cpu", interval, stats.LastUpdated, stats.CPUAverage())
+	}
+	history.Add("memory", interval, stats.LastUpdated, stats.MemoryPercent)
+	history.Add("disk", interval, stats.LastUpdated, stats.DiskPercent)
+}
+
+func recordResult(check HealthCheck) {
+	if check.Status != StatusUp && check.Status != StatusDegraded && check.Status != StatusDown {
+		return
+	}
+
+	interval := checkInterval(check)
+
+	up := 0.0
+	if check.Healthy() {
+		up = 1
+	}
+	history.Add(serviceSeries(check.Name, "up"), interval, check.CheckedAt, up)
+	history.Add(serviceSeries(check.Name, "latency"), interval, check.CheckedAt, milliseconds(check.Latency))
+}
+
+func parseWindow(window string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", window)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid window %q", window)
+	}
+
+	return duration, nil
+}
+
+func historyWindow(name, resolution, window string) (Samples, error) {
+	duration, err := parseWindow(window)
+	if err != nil {
+		return nil, err
+	}
+
+	return history.Since(name, resolution, time.Now().Add(-duration))
+}
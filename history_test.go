@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		window string
+		want   time.Duration
+		err    bool
+	}{
+		{window: "7d", want: 7 * 24 * time.Hour},
+		{window: "90m", want: 90 * time.Minute},
+		{window: "1h", want: time.Hour},
+		{window: "0d", err: true},
+		{window: "-1h", err: true},
+		{window: "d", err: true},
+		{window: "1.5d", err: true},
+		{window: "", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseWindow(test.window)
+		if test.err != (err != nil) {
+			t.Errorf("parseWindow(%q) error = %v", test.window, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseWindow(%q) = %s, want %s", test.window, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var httpClient = &http.Client{}
+
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+type StatusCodes []StatusRange
+
+func (codes *StatusCodes) UnmarshalJSON(data []byte) error {
+	var code int
+	if err := json.Unmarshal(data, &code); err == nil {
+		*codes = StatusCodes{{Min: code, Max: code}}
+		return nil
+	}
+
+	var spec string
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return fmt.Errorf("status_code must be a number or a string like \"200-299,301\"")
+	}
+
+	parsed, err := parseStatusCodes(spec)
+	if err != nil {
+		return err
+	}
+	*codes = parsed
+
+	return nil
+}
+
+func parseStatusCodes(spec string) (StatusCodes, error) {
+	var codes StatusCodes
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		low, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", part)
+		}
+		high := low
+		if isRange {
+			high, err = strconv.Atoi(strings.TrimSpace(to))
+			if err != nil || high < low {
+				return nil, fmt.Errorf("invalid status code range %q", part)
+			}
+		}
+
+		codes = append(codes, StatusRange{Min: low, Max: high})
+	}
+
+	return codes, nil
+}
+
+func (codes StatusCodes) Contains(code int) bool {
+	if len(codes) == 0 {
+		return code >= 200 && code <= 299
+	}
+
+	for _, r := range codes {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (codes StatusCodes) AcceptsRedirect() bool {
+	for _, r := range codes {
+		if r.Min <= 399 && r.Max >= 300 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func noRedirects(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+func traceTiming(ctx context.Context, timing *Timing) context.Context {
+	start := time.Now()
+	var dnsStart, connectStart, tlsStart time.Time
+
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { timing.DNS = time.Since(dnsStart) },
+		ConnectStart:      func(string, string) { connectStart = time.Now() },
+		ConnectDone:       func(string, string, error) { timing.Connect = time.Since(connectStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { timing.TLS = time.Since(tlsStart) },
+		GotFirstResponseByte: func() {
+			timing.FirstByte = time.Since(start)
+		},
+	})
+}
+
+func newRequest(ctx context.Context, check HealthCheck) (*http.Request, error) {
+	method := check.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if check.Body != "" {
+		body = strings.NewReader(check.Body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), check.Endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range check.Headers {
+		if strings.EqualFold(key, "Host") {
+			request.Host = value
+			continue
+		}
+		request.Header.Set(key, value)
+	}
+
+	if check.BearerTokenFile != "" {
+		token, err := os.ReadFile(check.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading bearer token: %w", err)
+		}
+		request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	return request, nil
+}
+
+func checkHTTP(ctx context.Context, check *HealthCheck) error {
+	request, err := newRequest(traceTiming(ctx, &check.Timing), *check)
+	if err != nil {
+		return err
+	}
+
+	client := httpClient
+	if check.client != nil {
+		client = check.client
+	}
+	if check.StatusCode.AcceptsRedirect() {
+		withoutRedirects := *client
+		withoutRedirects.CheckRedirect = noRedirects
+		client = &withoutRedirects
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	check.Response = response.StatusCode
+	if !check.StatusCode.Contains(response.StatusCode) {
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return fmt.Errorf("unexpected status code %d", response.StatusCode)
+		}
+
+		check.Status = StatusDegraded
+		check.Message = fmt.Sprintf("Unexpected status code %d", response.StatusCode)
+	}
+
+	if len(check.Assertions) > 0 {
+		body, err := io.ReadAll(io.LimitReader(response.Body, maxBodyBytes))
+		if err != nil {
+			return err
+		}
+		if err := checkAssertions(check.Assertions, body); err != nil {
+			return err
+		}
+	}
+
+	if err := checkCertificates(check, response.TLS); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseStatusCodes(t *testing.T) {
+	tests := []struct {
+		spec string
+		want StatusCodes
+		err  bool
+	}{
+		{spec: "200", want: StatusCodes{{200, 200}}},
+		{spec: "200-299,301", want: StatusCodes{{200, 299}, {301, 301}}},
+		{spec: " 200 - 204 , 404 ,", want: StatusCodes{{200, 204}, {404, 404}}},
+		{spec: "", want: nil},
+		{spec: "ok", err: true},
+		{spec: "299-200", err: true},
+		{spec: "200-", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseStatusCodes(test.spec)
+		if test.err != (err != nil) {
+			t.Errorf("parseStatusCodes(%q) error = %v", test.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseStatusCodes(%q) = %v, want %v", test.spec, got, test.want)
+		}
+	}
+}
+
+func TestStatusCodesUnmarshalJSON(t *testing.T) {
+	var check HealthCheck
+	if err := json.Unmarshal([]byte(`{"status_code": 204}`), &check); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(check.StatusCode, StatusCodes{{204, 204}}) {
+		t.Errorf("status_code 204 = %v", check.StatusCode)
+	}
+
+	if err := json.Unmarshal([]byte(`{"status_code": "200-299,301"}`), &check); err != nil {
+		t.Fatal(err)
+	}
+	for code, want := range map[int]bool{200: true, 250: true, 301: true, 302: false, 404: false} {
+		if got := check.StatusCode.Contains(code); got != want {
+			t.Errorf("Contains(%d) = %t, want %t", code, got, want)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"status_code": true}`), &check); err == nil {
+		t.Error("status_code true was accepted")
+	}
+}
+
+func TestCheckHTTPRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/gone", http.StatusMovedPermanently)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	config.TimeoutSeconds = defaultTimeoutSeconds
+
+	tests := []struct {
+		statusCode string
+		status     Status
+		response   int
+	}{
+		{statusCode: "200-299,301", status: StatusUp, response: http.StatusMovedPermanently},
+		{statusCode: "", status: StatusDown, response: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		check := HealthCheck{Endpoint: server.URL + "/old"}
+		if test.statusCode != "" {
+			codes, err := parseStatusCodes(test.statusCode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check.StatusCode = codes
+		}
+
+		result := runCheck(check)
+		if result.Status != test.status || result.Response != test.response {
+			t.Errorf("status_code %q: got %s with %d, want %s with %d", test.statusCode, result.Status, result.Response, test.status, test.response)
+		}
+	}
+}
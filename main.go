@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/tls"
 	_ "embed"
 	"encoding/json"
 	"fmt"
@@ -17,29 +18,143 @@ import (
 )
 
 type HealthCheck struct {
-	Name        string        `json:"name"`
-	Description string        `json:"description`
-	Icon        template.HTML `json:"icon"`
-	Endpoint    string        `json:"endpoint"`
-	StatusCode  int           `json:"status_code"`
-	Healthy     bool          `json:"healthy"`
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	Icon               template.HTML     `json:"icon"`
+	Type               string            `json:"type"`
+	Endpoint           string            `json:"endpoint"`
+	Method             string            `json:"method"`
+	Headers            map[string]string `json:"headers"`
+	BearerTokenFile    string            `json:"bearer_token_file"`
+	Body               string            `json:"body"`
+	StatusCode         StatusCodes       `json:"status_code"`
+	Timeout            int               `json:"timeout_seconds"`
+	Interval           int               `json:"interval_seconds"`
+	DegradedLatency    int               `json:"degraded_latency_ms"`
+	DegradedCertDays   int               `json:"degraded_cert_days"`
+	DownCertDays       int               `json:"down_cert_days"`
+	Paused             bool              `json:"paused"`
+	CAFile             string            `json:"ca_file"`
+	CertFile           string            `json:"cert_file"`
+	KeyFile            string            `json:"key_file"`
+	ServerName         string            `json:"server_name"`
+	InsecureSkipVerify bool              `json:"insecure_skip_verify"`
+	Query              string            `json:"query"`
+	RecordType         string            `json:"record_type"`
+	Expected           []string          `json:"expected"`
+	Count              int               `json:"count"`
+	Command            []string          `json:"command"`
+	Env                map[string]string `json:"env"`
+	WorkingDir         string            `json:"working_dir"`
+	ProcessName        string            `json:"process_name"`
+	CmdlinePattern     string            `json:"cmdline_pattern"`
+	Pidfile            string            `json:"pidfile"`
+	MinCount           int               `json:"min_count"`
+	MaxAgeHours        int               `json:"max_age_hours"`
+	MinSizeBytes       uint64            `json:"min_size_bytes"`
+	Retries            int               `json:"retries"`
+	RetryDelay         int               `json:"retry_delay_ms"`
+	FailureThreshold   int               `json:"failure_threshold"`
+	SuccessThreshold   int               `json:"success_threshold"`
+	Assertions         []Assertion       `json:"assertions"`
+
+	Status       Status         `json:"-"`
+	TimedOut     bool           `json:"-"`
+	Message      string         `json:"-"`
+	Response     int            `json:"-"`
+	Latency      time.Duration  `json:"-"`
+	Timing       Timing         `json:"-"`
+	Ping         PingStats      `json:"-"`
+	Unit         UnitState      `json:"-"`
+	Container    ContainerState `json:"-"`
+	Processes    ProcessStats   `json:"-"`
+	File         FileStats      `json:"-"`
+	CertExpiry   time.Time      `json:"-"`
+	Certificates []Certificate  `json:"-"`
+	Failures     int            `json:"-"`
+	Successes    int            `json:"-"`
+	CheckedAt    time.Time      `json:"-"`
+
+	id        string
+	tlsConfig *tls.Config
+	client    *http.Client
+}
+
+func (check HealthCheck) Healthy() bool {
+	return check.Status == StatusUp || check.Status == StatusDegraded
+}
+
+type Status string
+
+const (
+	StatusUnknown  Status = "unknown"
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+	StatusPaused   Status = "paused"
+)
+
+var statuses = []Status{StatusUp, StatusDegraded, StatusDown, StatusUnknown, StatusPaused}
+
+func countStatuses(checks []HealthCheck) map[string]int {
+	counts := make(map[string]int, len(statuses))
+	for _, status := range statuses {
+		counts[string(status)] = 0
+	}
+	for _, check := range checks {
+		counts[string(check.Status)]++
+	}
+
+	return counts
+}
+
+type Timing struct {
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
 }
 
 type SystemStats struct {
-	CPU           []float64
-	MemoryUsed    uint64
-	MemoryTotal   uint64
-	MemoryPercent float64
-	DiskUsed      uint64
-	DiskTotal     uint64
-	DiskPercent   float64
-	LastUpdated   time.Time
+	CPU           []float64 `json:"cpu_percent"`
+	MemoryUsed    uint64    `json:"memory_used_bytes"`
+	MemoryTotal   uint64    `json:"memory_total_bytes"`
+	MemoryPercent float64   `json:"memory_percent"`
+	DiskUsed      uint64    `json:"disk_used_bytes"`
+	DiskTotal     uint64    `json:"disk_total_bytes"`
+	DiskPercent   float64   `json:"disk_percent"`
+	LastUpdated   time.Time `json:"last_updated"`
+}
+
+func (stats SystemStats) CPUAverage() float64 {
+	if len(stats.CPU) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, percent := range stats.CPU {
+		total += percent
+	}
+
+	return total / float64(len(stats.CPU))
 }
 
 type Config struct {
 	Site                   string        `json:"site"`
 	Port                   int           `json:"port"`
 	RefreshIntervalSeconds int           `json:"refresh_interval_seconds"`
+	TimeoutSeconds         int           `json:"timeout_seconds"`
+	MaxConcurrentChecks    int           `json:"max_concurrent_checks"`
+	DegradedLatencyMs      int           `json:"degraded_latency_ms"`
+	DegradedCertDays       int           `json:"degraded_cert_days"`
+	DownCertDays           int           `json:"down_cert_days"`
+	DockerSocket           string        `json:"docker_socket"`
+	DockerDiscovery        bool          `json:"docker_discovery"`
+	DiscoveryInterval      int           `json:"discovery_interval_seconds"`
+	HistoryRawMinutes      int           `json:"history_raw_minutes"`
+	HistoryMinuteHours     int           `json:"history_minute_hours"`
+	HistoryHourDays        int           `json:"history_hour_days"`
+	DataDir                string        `json:"data_dir"`
 	HealthChecks           []HealthCheck `json:"healthchecks"`
 }
 
@@ -47,8 +162,11 @@ type TemplateData struct {
 	Config   Config
 	Stats    SystemStats
 	Services []HealthCheck
+	Counts   map[string]int
 	Uptime   time.Duration
 	Updated  time.Duration
+	Range    Range
+	Ranges   []Range
 }
 
 func formatBytes(b uint64) string {
@@ -72,9 +190,20 @@ func formatPercent(p float64) string {
 	return fmt.Sprintf("%.2f%%", p)
 }
 
-func collectStats() {
+func formatLatency(d time.Duration) string {
+	if d < 10*time.Millisecond {
+		return fmt.Sprintf("%.1f ms", float64(d)/float64(time.Millisecond))
+	}
+	if d < time.Second {
+		return fmt.Sprintf("%d ms", d.Milliseconds())
+	}
+
+	return fmt.Sprintf("%.2f s", d.Seconds())
+}
+
+func collectSystemStats() {
 	for {
-		cpuPercent, err := cpu.Percent(0, false)
+		cpuPercent, err := cpu.Percent(0, true)
 		if err != nil || len(cpuPercent) == 0 {
 			log.Printf("Error getting CPU percent: %v", err)
 		}
@@ -89,27 +218,7 @@ func collectStats() {
 			log.Printf("Error getting disk info: %v", err)
 		}
 
-		newHealthchecks := healthchecks
-		for i, healthcheck := range healthchecks {
-			newHealthchecks[i].Healthy = true
-
-			response, err := http.Get(healthcheck.Endpoint)
-			if err != nil {
-				log.Printf("Error checking health: %v", err)
-				newHealthchecks[i].Healthy = false
-				continue
-			}
-
-			if response.StatusCode != healthcheck.StatusCode {
-				newHealthchecks[i].Healthy = false
-			}
-
-			response.Body.Close()
-		}
-
-		reportMutex.Lock()
-		healthchecks = newHealthchecks
-		stats = SystemStats{
+		newStats := SystemStats{
 			CPU:           cpuPercent,
 			MemoryUsed:    memInfo.Used,
 			MemoryTotal:   memInfo.Total,
@@ -119,8 +228,13 @@ func collectStats() {
 			DiskPercent:   diskInfo.UsedPercent,
 			LastUpdated:   time.Now(),
 		}
+
+		reportMutex.Lock()
+		stats = newStats
 		reportMutex.Unlock()
 
+		recordStats(newStats)
+
 		time.Sleep(time.Duration(config.RefreshIntervalSeconds) * time.Second)
 	}
 }
@@ -130,6 +244,7 @@ var (
 	healthchecks []HealthCheck
 	stats        SystemStats
 	reportMutex  sync.RWMutex
+	startTime    time.Time
 )
 
 func main() {
@@ -143,20 +258,73 @@ func main() {
 		log.Fatalf("Failed to parse config.json: %v", err)
 	}
 
-	healthchecks = config.HealthChecks
+	if config.RefreshIntervalSeconds <= 0 {
+		config.RefreshIntervalSeconds = defaultRefreshIntervalSeconds
+	}
+	if config.TimeoutSeconds <= 0 {
+		config.TimeoutSeconds = defaultTimeoutSeconds
+	}
+
+	if config.MaxConcurrentChecks <= 0 {
+		config.MaxConcurrentChecks = defaultMaxConcurrentChecks
+	}
+
+	if config.DegradedCertDays <= 0 {
+		config.DegradedCertDays = defaultDegradedCertDays
+	}
+
+	if config.DockerSocket == "" {
+		config.DockerSocket = defaultDockerSocket
+	}
+	dockerClient = newDockerClient(config.DockerSocket)
+	if config.DiscoveryInterval <= 0 {
+		config.DiscoveryInterval = defaultDiscoveryInterval
+	}
+
+	if config.HistoryRawMinutes <= 0 {
+		config.HistoryRawMinutes = defaultHistoryRawMinutes
+	}
+	if config.HistoryMinuteHours <= 0 {
+		config.HistoryMinuteHours = defaultHistoryMinuteHours
+	}
+	if config.HistoryHourDays <= 0 {
+		config.HistoryHourDays = defaultHistoryHourDays
+	}
+
+	if config.DataDir == "" {
+		config.DataDir = defaultDataDir
+	}
+
+	if err := history.Load(config.DataDir); err != nil {
+		log.Fatalf("Failed to load history from %s: %v", config.DataDir, err)
+	}
+	if err := history.Compact(config.DataDir); err != nil {
+		log.Printf("Error compacting history: %v", err)
+	}
+	go persistHistory(config.DataDir)
 
 	funcs := template.FuncMap{
-		"FormatPercent": formatPercent,
-		"FormatBytes":   formatBytes,
+		"FormatPercent":     formatPercent,
+		"FormatBytes":       formatBytes,
+		"FormatLatency":     formatLatency,
+		"History":           historyWindow,
+		"ServiceSeries":     serviceSeries,
+		"Sparkline":         sparkline,
+		"Availability":      availability,
+		"DailyAvailability": dailyAvailability,
 	}
 	tmpl, err := template.New("template.gohtml").Funcs(funcs).ParseFiles("template.gohtml")
 	if err != nil {
 		log.Fatalf("Error parsing template: %v", err)
 	}
 
-	startTime := time.Now()
+	startTime = time.Now()
 
-	go collectStats()
+	go collectSystemStats()
+	scheduleHealthChecks()
+	if config.DockerDiscovery {
+		go discoverContainers()
+	}
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		reportMutex.RLock()
@@ -166,8 +334,11 @@ func main() {
 			Config:   config,
 			Stats:    stats,
 			Services: healthchecks,
+			Counts:   countStatuses(healthchecks),
 			Uptime:   time.Since(startTime).Round(time.Second),
 			Updated:  time.Since(stats.LastUpdated).Round(time.Second),
+			Range:    findRange(r.URL.Query().Get("range")),
+			Ranges:   ranges,
 		}
 
 		if err := tmpl.Execute(w, templateData); err != nil {
@@ -175,6 +346,9 @@ func main() {
 		}
 	})
 
+	registerAPI()
+	registerMetrics()
+
 	port := fmt.Sprintf(":%d", config.Port)
 	log.Println("Serving system stats on http://localhost" + port)
 	log.Fatal(http.ListenAndServe(port, nil))
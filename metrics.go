@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeMetricHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeMetric(w io.Writer, name string, labels [][2]string, value float64) {
+	if len(labels) == 0 {
+		fmt.Fprintf(w, "%s %s\n", name, strconv.FormatFloat(value, 'g', -1, 64))
+		return
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for _, label := range labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, label[0], labelEscaper.Replace(label[1])))
+	}
+	fmt.Fprintf(w, "%s{%s} %s\n", name, strings.Join(pairs, ","), strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func writeMetrics(w io.Writer, stats SystemStats, checks []HealthCheck, uptime time.Duration) {
+	writeMetricHeader(w, "status_uptime_seconds", "Seconds since the reporter started.", "gauge")
+	writeMetric(w, "status_uptime_seconds", nil, uptime.Seconds())
+
+	writeMetricHeader(w, "status_cpu_usage_percent", "CPU usage percent per core.", "gauge")
+	for i, percent := range stats.CPU {
+		writeMetric(w, "status_cpu_usage_percent", [][2]string{{"cpu", strconv.Itoa(i)}}, percent)
+	}
+
+	writeMetricHeader(w, "status_memory_used_bytes", "Memory in use.", "gauge")
+	writeMetric(w, "status_memory_used_bytes", nil, float64(stats.MemoryUsed))
+	writeMetricHeader(w, "status_memory_total_bytes", "Total memory.", "gauge")
+	writeMetric(w, "status_memory_total_bytes", nil, float64(stats.MemoryTotal))
+
+	writeMetricHeader(w, "status_disk_used_bytes", "Disk space in use on /.", "gauge")
+	writeMetric(w, "status_disk_used_bytes", nil, float64(stats.DiskUsed))
+	writeMetricHeader(w, "status_disk_total_bytes", "Total disk space on /.", "gauge")
+	writeMetric(w, "status_disk_total_bytes", nil, float64(stats.DiskTotal))
+
+	writeMetricHeader(w, "status_service_up", "Whether the service health check passed.", "gauge")
+	for _, check := range checks {
+		up := 0.0
+		if check.Healthy() {
+			up = 1
+		}
+		writeMetric(w, "status_service_up", [][2]string{{"name", check.Name}}, up)
+	}
+
+	writeMetricHeader(w, "status_service_status", "Current status of the service, 1 for the active status.", "gauge")
+	for _, check := range checks {
+		for _, status := range statuses {
+			active := 0.0
+			if check.Status == status {
+				active = 1
+			}
+			writeMetric(w, "status_service_status", [][2]string{{"name", check.Name}, {"status", string(status)}}, active)
+		}
+	}
+
+	writeMetricHeader(w, "status_service_status_code", "HTTP status code returned by the service, 0 if no response.", "gauge")
+	for _, check := range checks {
+		writeMetric(w, "status_service_status_code", [][2]string{{"name", check.Name}}, float64(check.Response))
+	}
+
+	writeMetricHeader(w, "status_service_response_seconds", "Duration of the last health check.", "gauge")
+	for _, check := range checks {
+		writeMetric(w, "status_service_response_seconds", [][2]string{{"name", check.Name}}, check.Latency.Seconds())
+	}
+
+	writeMetricHeader(w, "status_service_packet_loss_ratio", "Fraction of ping probes without a reply.", "gauge")
+	for _, check := range checks {
+		if check.Ping.Sent > 0 {
+			writeMetric(w, "status_service_packet_loss_ratio", [][2]string{{"name", check.Name}}, check.Ping.Loss())
+		}
+	}
+
+	writeMetricHeader(w, "status_service_restarts", "Number of times the systemd unit or container has been restarted.", "gauge")
+	for _, check := range checks {
+		if check.Unit.ActiveState != "" {
+			writeMetric(w, "status_service_restarts", [][2]string{{"name", check.Name}}, float64(check.Unit.Restarts))
+		}
+		if check.Container.State != "" {
+			writeMetric(w, "status_service_restarts", [][2]string{{"name", check.Name}}, float64(check.Container.Restarts))
+		}
+	}
+
+	writeMetricHeader(w, "status_service_processes", "Number of running processes matching the check.", "gauge")
+	for _, check := range checks {
+		if check.Type == "process" {
+			writeMetric(w, "status_service_processes", [][2]string{{"name", check.Name}}, float64(check.Processes.Count))
+		}
+	}
+
+	writeMetricHeader(w, "status_service_process_cpu_percent", "Combined CPU usage percent of matching processes.", "gauge")
+	for _, check := range checks {
+		if check.Type == "process" {
+			writeMetric(w, "status_service_process_cpu_percent", [][2]string{{"name", check.Name}}, check.Processes.CPUPercent)
+		}
+	}
+
+	writeMetricHeader(w, "status_service_process_rss_bytes", "Combined resident memory of matching processes.", "gauge")
+	for _, check := range checks {
+		if check.Type == "process" {
+			writeMetric(w, "status_service_process_rss_bytes", [][2]string{{"name", check.Name}}, float64(check.Processes.RSS))
+		}
+	}
+
+	writeMetricHeader(w, "status_service_file_modified_timestamp_seconds", "Unix time the newest file matching the check was modified.", "gauge")
+	for _, check := range checks {
+		if check.File.Count > 0 {
+			writeMetric(w, "status_service_file_modified_timestamp_seconds", [][2]string{{"name", check.Name}}, float64(check.File.Modified.Unix()))
+		}
+	}
+
+	writeMetricHeader(w, "status_service_cert_expiry_timestamp_seconds", "Unix time the earliest certificate in the service's chain expires.", "gauge")
+	for _, check := range checks {
+		if !check.CertExpiry.IsZero() {
+			writeMetric(w, "status_service_cert_expiry_timestamp_seconds", [][2]string{{"name", check.Name}}, float64(check.CertExpiry.Unix()))
+		}
+	}
+}
+
+func registerMetrics() {
+	http.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		reportMutex.RLock()
+		currentStats := stats
+		checks := make([]HealthCheck, len(healthchecks))
+		copy(checks, healthchecks)
+		reportMutex.RUnlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w, currentStats, checks, time.Since(startTime))
+	})
+}
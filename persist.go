@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDataDir  = "data"
+	snapshotFile    = "history.json"
+	logPrefix       = "history."
+	logSuffix       = ".log"
+	syncInterval    = time.Minute
+	compactInterval = time.Hour
+)
+
+type logEntry struct {
+	Series   string        `json:"s"`
+	Interval time.Duration `json:"i"`
+	Time     time.Time     `json:"t"`
+	Value    float64       `json:"v"`
+}
+
+type seriesSnapshot struct {
+	Interval      time.Duration `json:"interval"`
+	Raw           Samples       `json:"raw"`
+	Minute        Samples       `json:"minute"`
+	Hour          Samples       `json:"hour"`
+	CurrentMinute Sample        `json:"current_minute"`
+	CurrentHour   Sample        `json:"current_hour"`
+}
+
+// Each compaction starts a new numbered log. The snapshot records the first
+// generation it doesn't include, so older logs left behind by a crash are
+// never replayed twice.
+type snapshot struct {
+	Generation int                       `json:"generation"`
+	Series     map[string]seriesSnapshot `json:"series"`
+}
+
+func logPath(dir string, generation int) string {
+	return filepath.Join(dir, logPrefix+strconv.Itoa(generation)+logSuffix)
+}
+
+func logGenerations(dir string) ([]int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, logPrefix+"*"+logSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	var generations []int
+	for _, path := range paths {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), logPrefix), logSuffix)
+		if generation, err := strconv.Atoi(name); err == nil {
+			generations = append(generations, generation)
+		}
+	}
+	slices.Sort(generations)
+
+	return generations, nil
+}
+
+func (h *History) appendLog(entry logEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Error encoding history: %v", err)
+		return
+	}
+
+	if _, err := h.log.Write(append(line, '\n')); err != nil {
+		log.Printf("Error writing history: %v", err)
+	}
+}
+
+func (h *History) loadSnapshot(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var saved snapshot
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	for name, savedSeries := range saved.Series {
+		s := newSeries(savedSeries.Interval)
+		for _, sample := range savedSeries.Raw {
+			s.raw.push(sample)
+		}
+		for _, sample := range savedSeries.Minute {
+			s.minute.push(sample)
+		}
+		for _, sample := range savedSeries.Hour {
+			s.hour.push(sample)
+		}
+		s.currentMinute = savedSeries.CurrentMinute
+		s.currentHour = savedSeries.CurrentHour
+		h.series[name] = s
+	}
+
+	return saved.Generation, nil
+}
+
+func (h *History) replayLog(file *os.File) error {
+	reader := bufio.NewReader(file)
+
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		offset += int64(len(line))
+
+		var entry logEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			log.Printf("Skipping corrupt history entry at offset %d", offset-int64(len(line)))
+			continue
+		}
+		h.add(entry.Series, entry.Interval, entry.Time, entry.Value)
+	}
+
+	// Drop a partially written last line so new entries start on a fresh line.
+	return file.Truncate(offset)
+}
+
+func (h *History) Load(dir string) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	generation, err := h.loadSnapshot(filepath.Join(dir, snapshotFile))
+	if err != nil {
+		return err
+	}
+
+	generations, err := logGenerations(dir)
+	if err != nil {
+		return err
+	}
+	for _, g := range generations {
+		if g < generation {
+			os.Remove(logPath(dir, g))
+			continue
+		}
+
+		file, err := os.OpenFile(logPath(dir, g), os.O_RDWR|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		err = h.replayLog(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		generation = g
+	}
+
+	file, err := os.OpenFile(logPath(dir, generation), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	h.log = file
+	h.generation = generation
+
+	return nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if err := temp.Chmod(0o644); err != nil {
+		temp.Close()
+		return err
+	}
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
+
+func (h *History) Compact(dir string) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	next := h.generation + 1
+	file, err := os.OpenFile(logPath(dir, next), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := h.log.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	h.log.Close()
+	h.log = file
+	h.generation = next
+
+	saved := snapshot{
+		Generation: next,
+		Series:     make(map[string]seriesSnapshot, len(h.series)),
+	}
+	for name, s := range h.series {
+		saved.Series[name] = seriesSnapshot{
+			Interval:      s.interval,
+			Raw:           s.raw.since(time.Time{}),
+			Minute:        s.minute.since(time.Time{}),
+			Hour:          s.hour.since(time.Time{}),
+			CurrentMinute: s.currentMinute,
+			CurrentHour:   s.currentHour,
+		}
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, snapshotFile), data); err != nil {
+		return err
+	}
+
+	generations, err := logGenerations(dir)
+	if err != nil {
+		return err
+	}
+	for _, g := range generations {
+		if g < next {
+			if err := os.Remove(logPath(dir, g)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (h *History) Sync() error {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return h.log.Sync()
+}
+
+func persistHistory(dir string) {
+	syncTicker := time.NewTicker(syncInterval)
+	compactTicker := time.NewTicker(compactInterval)
+
+	for {
+		select {
+		case <-syncTicker.C:
+			if err := history.Sync(); err != nil {
+				log.Printf("Error syncing history: %v", err)
+			}
+		case <-compactTicker.C:
+			if err := history.Compact(dir); err != nil {
+				log.Printf("Error compacting history: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestHistory(t *testing.T, dir string) *History {
+	t.Helper()
+
+	config.HistoryRawMinutes = defaultHistoryRawMinutes
+	config.HistoryMinuteHours = defaultHistoryMinuteHours
+	config.HistoryHourDays = defaultHistoryHourDays
+
+	h := &History{series: make(map[string]*series)}
+	if err := h.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.log.Close() })
+
+	return h
+}
+
+func TestReplayLogTornLine(t *testing.T) {
+	dir := t.TempDir()
+	path := logPath(dir, 0)
+
+	valid := `{"s":"cpu","i":10000000000,"t":"2025-01-01T12:00:00Z","v":10}` + "\n" +
+		`not json` + "\n" +
+		`{"s":"cpu","i":10000000000,"t":"2025-01-01T12:00:10Z","v":20}` + "\n"
+	torn := `{"s":"cpu","i":10000000000,"t":"2025-01-0`
+	if err := os.WriteFile(path, []byte(valid+torn), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := newTestHistory(t, dir)
+
+	samples, err := h.Since("cpu", "raw", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 || samples[0].Value != 10 || samples[1].Value != 20 {
+		t.Fatalf("replayed %v, want the two valid entries", samples)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != valid {
+		t.Fatalf("log after replay = %q, want the torn line dropped", data)
+	}
+
+	h.Add("cpu", 10*time.Second, time.Date(2025, 1, 1, 12, 0, 20, 0, time.UTC), 30)
+
+	reloaded := newTestHistory(t, dir)
+	samples, err = reloaded.Since("cpu", "raw", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 3 || samples[2].Value != 30 {
+		t.Fatalf("reloaded %v, want the new entry after the valid ones", samples)
+	}
+}
+
+func TestLoadSkipsCompactedEntries(t *testing.T) {
+	dir := t.TempDir()
+	h := newTestHistory(t, dir)
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 180 {
+		h.Add("cpu", 10*time.Second, start.Add(time.Duration(i)*10*time.Second), float64(i))
+	}
+
+	logData, err := os.ReadFile(logPath(dir, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Compact(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash after the snapshot was written but before the old
+	// log was removed.
+	if err := os.WriteFile(logPath(dir, 0), logData, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := newTestHistory(t, dir)
+	for _, resolution := range []string{"raw", "minute", "hour"} {
+		want, _ := h.Since("cpu", resolution, time.Time{})
+		got, _ := reloaded.Since("cpu", resolution, time.Time{})
+		if len(got) != len(want) {
+			t.Errorf("%s: reloaded %d samples, want %d", resolution, len(got), len(want))
+			continue
+		}
+		for i := range want {
+			if !got[i].Time.Equal(want[i].Time) || got[i].Count != want[i].Count {
+				t.Errorf("%s: sample %d = %v, want %v", resolution, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestLoadKeepsEntriesAfterClockStep(t *testing.T) {
+	dir := t.TempDir()
+	h := newTestHistory(t, dir)
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 6 {
+		h.Add("cpu", 10*time.Second, start.Add(time.Duration(i)*10*time.Second), 1)
+	}
+	if err := h.Compact(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// The clock is stepped back an hour, e.g. by NTP after booting without
+	// a real-time clock.
+	stepped := start.Add(-time.Hour)
+	for i := range 3 {
+		h.Add("cpu", 10*time.Second, stepped.Add(time.Duration(i)*10*time.Second), 2)
+	}
+
+	reloaded := newTestHistory(t, dir)
+	samples, err := reloaded.Since("cpu", "raw", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 9 {
+		t.Fatalf("reloaded %d samples, want 9", len(samples))
+	}
+
+	var stepCount int
+	for _, sample := range samples {
+		if sample.Value == 2 {
+			stepCount++
+		}
+	}
+	if stepCount != 3 {
+		t.Errorf("reloaded %d samples from after the clock step, want 3", stepCount)
+	}
+
+	if _, err := os.Stat(logPath(dir, 0)); !os.IsNotExist(err) {
+		t.Errorf("compacted log still exists: %v", err)
+	}
+}
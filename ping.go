@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	defaultPingCount = 3
+	pingProbeTimeout = time.Second
+)
+
+type PingStats struct {
+	Sent     int
+	Received int
+	Min      time.Duration
+	Avg      time.Duration
+	Max      time.Duration
+}
+
+func (stats PingStats) Loss() float64 {
+	if stats.Sent == 0 {
+		return 0
+	}
+
+	return float64(stats.Sent-stats.Received) / float64(stats.Sent)
+}
+
+type pinger struct {
+	conn       *icmp.PacketConn
+	privileged bool
+	protocol   int
+	echoType   icmp.Type
+	replyType  icmp.Type
+}
+
+func newPinger(ip net.IP) (*pinger, error) {
+	p := &pinger{protocol: 1, echoType: ipv4.ICMPTypeEcho, replyType: ipv4.ICMPTypeEchoReply}
+	network, rawNetwork, address := "udp4", "ip4:icmp", "0.0.0.0"
+	if ip.To4() == nil {
+		p.protocol, p.echoType, p.replyType = 58, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		network, rawNetwork, address = "udp6", "ip6:ipv6-icmp", "::"
+	}
+
+	conn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		var rawErr error
+		conn, rawErr = icmp.ListenPacket(rawNetwork, address)
+		if rawErr != nil {
+			return nil, fmt.Errorf("opening ICMP socket: %w", errors.Join(err, rawErr))
+		}
+		p.privileged = true
+	}
+	p.conn = conn
+
+	return p, nil
+}
+
+func (p *pinger) destination(ip net.IP) net.Addr {
+	if p.privileged {
+		return &net.IPAddr{IP: ip}
+	}
+
+	return &net.UDPAddr{IP: ip}
+}
+
+func (p *pinger) probe(ctx context.Context, ip net.IP, id, seq int) (time.Duration, error) {
+	message := icmp.Message{
+		Type: p.echoType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("status")},
+	}
+	packet, err := message.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(pingProbeTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := p.conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if _, err := p.conn.WriteTo(packet, p.destination(ip)); err != nil {
+		return 0, err
+	}
+
+	buffer := make([]byte, 1500)
+	for {
+		n, _, err := p.conn.ReadFrom(buffer)
+		if err != nil {
+			return 0, err
+		}
+
+		reply, err := icmp.ParseMessage(p.protocol, buffer[:n])
+		if err != nil || reply.Type != p.replyType {
+			continue
+		}
+
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq || (p.privileged && echo.ID != id) {
+			continue
+		}
+
+		return time.Since(start), nil
+	}
+}
+
+func checkPing(ctx context.Context, check *HealthCheck) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, check.Endpoint)
+	if err != nil {
+		return err
+	}
+	ip := addrs[0].IP
+
+	p, err := newPinger(ip)
+	if err != nil {
+		return err
+	}
+	defer p.conn.Close()
+
+	count := check.Count
+	if count <= 0 {
+		count = defaultPingCount
+	}
+
+	var total time.Duration
+	id := os.Getpid() & 0xffff
+	for seq := 1; seq <= count && ctx.Err() == nil; seq++ {
+		check.Ping.Sent++
+
+		rtt, err := p.probe(ctx, ip, id, seq)
+		if err != nil {
+			continue
+		}
+
+		check.Ping.Received++
+		total += rtt
+		if check.Ping.Min == 0 || rtt < check.Ping.Min {
+			check.Ping.Min = rtt
+		}
+		if rtt > check.Ping.Max {
+			check.Ping.Max = rtt
+		}
+	}
+
+	if check.Ping.Received == 0 {
+		return fmt.Errorf("no replies from %s, 100%% packet loss", ip)
+	}
+
+	check.Ping.Avg = total / time.Duration(check.Ping.Received)
+	check.Latency = check.Ping.Avg
+	if check.Ping.Received < check.Ping.Sent {
+		check.Status = StatusDegraded
+		check.Message = fmt.Sprintf("%.0f%% packet loss", check.Ping.Loss()*100)
+	}
+
+	return nil
+}
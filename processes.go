@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+const processSampleInterval = 250 * time.Millisecond
+
+type ProcessStats struct {
+	Count      int
+	CPUPercent float64
+	RSS        uint64
+}
+
+func matchProcesses(ctx context.Context, check HealthCheck) ([]*process.Process, error) {
+	if check.Pidfile != "" {
+		contents, err := os.ReadFile(check.Pidfile)
+		if err != nil {
+			return nil, fmt.Errorf("reading pidfile: %w", err)
+		}
+
+		pid, err := strconv.ParseInt(strings.TrimSpace(string(contents)), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pidfile %s: %w", check.Pidfile, err)
+		}
+
+		p, err := process.NewProcessWithContext(ctx, int32(pid))
+		if errors.Is(err, process.ErrorProcessNotRunning) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return []*process.Process{p}, nil
+	}
+
+	if check.ProcessName == "" && check.CmdlinePattern == "" {
+		return nil, errors.New("no process_name, cmdline_pattern or pidfile configured")
+	}
+
+	var pattern *regexp.Regexp
+	if check.CmdlinePattern != "" {
+		var err error
+		pattern, err = regexp.Compile(check.CmdlinePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cmdline_pattern: %w", err)
+		}
+	}
+
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	self := int32(os.Getpid())
+	var matches []*process.Process
+	for _, p := range processes {
+		if p.Pid == self {
+			continue
+		}
+
+		if check.ProcessName != "" {
+			name, err := p.NameWithContext(ctx)
+			if err != nil || name != check.ProcessName {
+				continue
+			}
+		}
+
+		if pattern != nil {
+			cmdline, err := p.CmdlineWithContext(ctx)
+			if err != nil || !pattern.MatchString(cmdline) {
+				continue
+			}
+		}
+
+		matches = append(matches, p)
+	}
+
+	return matches, nil
+}
+
+func cpuSeconds(ctx context.Context, p *process.Process) float64 {
+	times, err := p.TimesWithContext(ctx)
+	if err != nil {
+		return 0
+	}
+
+	return times.User + times.System
+}
+
+func checkProcess(ctx context.Context, check *HealthCheck) error {
+	matches, err := matchProcesses(ctx, *check)
+	if err != nil {
+		return err
+	}
+
+	before := make([]float64, len(matches))
+	for i, p := range matches {
+		before[i] = cpuSeconds(ctx, p)
+		if memory, err := p.MemoryInfoWithContext(ctx); err == nil {
+			check.Processes.RSS += memory.RSS
+		}
+	}
+
+	start := time.Now()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(processSampleInterval):
+	}
+	elapsed := time.Since(start).Seconds()
+
+	for i, p := range matches {
+		if running, err := p.IsRunningWithContext(ctx); err != nil || !running {
+			continue
+		}
+
+		check.Processes.Count++
+		check.Processes.CPUPercent += (cpuSeconds(ctx, p) - before[i]) / elapsed * 100
+	}
+
+	minCount := check.MinCount
+	if minCount <= 0 {
+		minCount = 1
+	}
+	if check.Processes.Count < minCount {
+		return fmt.Errorf("%d of %d processes running", check.Processes.Count, minCount)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+const (
+	sparklineWidth  = 100
+	sparklineHeight = 24
+)
+
+type Range struct {
+	Name       string
+	Resolution string
+}
+
+var ranges = []Range{
+	{Name: "1h", Resolution: "raw"},
+	{Name: "24h", Resolution: "minute"},
+	{Name: "7d", Resolution: "hour"},
+}
+
+func findRange(name string) Range {
+	for _, r := range ranges {
+		if r.Name == name {
+			return r
+		}
+	}
+
+	return ranges[0]
+}
+
+func sparkline(samples Samples, ceiling float64) template.HTML {
+	if len(samples) < 2 {
+		return ""
+	}
+
+	if ceiling <= 0 {
+		ceiling = samples.Peak()
+	}
+	if ceiling <= 0 {
+		ceiling = 1
+	}
+
+	start := samples[0].Time
+	span := samples[len(samples)-1].Time.Sub(start).Seconds()
+	if span <= 0 {
+		span = 1
+	}
+
+	points := make([]string, 0, len(samples))
+	for _, sample := range samples {
+		x := sample.Time.Sub(start).Seconds() / span * sparklineWidth
+		y := sparklineHeight - min(sample.Value/ceiling, 1)*sparklineHeight
+		points = append(points, fmt.Sprintf("%.2f,%.2f", x, y))
+	}
+	line := strings.Join(points, " ")
+
+	return template.HTML(fmt.Sprintf(
+		`<svg class="sparkline" viewBox="0 0 %d %d" preserveAspectRatio="none" aria-hidden="true">`+
+			`<polygon points="0,%d %s %d,%d" />`+
+			`<polyline points="%s" vector-effect="non-scaling-stroke" />`+
+			`</svg>`,
+		sparklineWidth, sparklineHeight,
+		sparklineHeight, line, sparklineWidth, sparklineHeight,
+		line,
+	))
+}
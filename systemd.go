@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+var errUnitNotFound = errors.New("not found")
+
+type UnitState struct {
+	ActiveState string
+	SubState    string
+	Result      string
+	ServiceType string
+	Restarts    int
+	Since       time.Time
+}
+
+func unitName(endpoint string) string {
+	if !strings.Contains(endpoint, ".") {
+		return endpoint + ".service"
+	}
+
+	return endpoint
+}
+
+func unitStateDBus(ctx context.Context, conn *dbus.Conn, unit string) (UnitState, error) {
+	var state UnitState
+
+	var path dbus.ObjectPath
+	manager := conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1")
+	if err := manager.CallWithContext(ctx, "org.freedesktop.systemd1.Manager.LoadUnit", 0, unit).Store(&path); err != nil {
+		return state, err
+	}
+
+	object := conn.Object("org.freedesktop.systemd1", path)
+
+	var properties map[string]dbus.Variant
+	if err := object.CallWithContext(ctx, "org.freedesktop.DBus.Properties.GetAll", 0, "org.freedesktop.systemd1.Unit").Store(&properties); err != nil {
+		return state, err
+	}
+	var loadState string
+	properties["LoadState"].Store(&loadState)
+	if loadState == "not-found" {
+		return state, fmt.Errorf("unit %s %w", unit, errUnitNotFound)
+	}
+
+	properties["ActiveState"].Store(&state.ActiveState)
+	properties["SubState"].Store(&state.SubState)
+	var since uint64
+	if properties["StateChangeTimestamp"].Store(&since) == nil && since > 0 {
+		state.Since = time.UnixMicro(int64(since))
+	}
+
+	if strings.HasSuffix(unit, ".service") {
+		if err := object.CallWithContext(ctx, "org.freedesktop.DBus.Properties.GetAll", 0, "org.freedesktop.systemd1.Service").Store(&properties); err != nil {
+			return state, err
+		}
+
+		var restarts uint32
+		properties["Result"].Store(&state.Result)
+		properties["Type"].Store(&state.ServiceType)
+		properties["NRestarts"].Store(&restarts)
+		state.Restarts = int(restarts)
+	}
+
+	return state, nil
+}
+
+func unitStateSystemctl(ctx context.Context, unit string) (UnitState, error) {
+	var state UnitState
+
+	output, err := exec.CommandContext(ctx, "systemctl", "show", unit, "--timestamp=unix",
+		"--property=LoadState,ActiveState,SubState,Result,Type,NRestarts,StateChangeTimestamp").Output()
+	if err != nil {
+		return state, fmt.Errorf("systemctl show %s: %w", unit, err)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "LoadState":
+			if value == "not-found" {
+				return state, fmt.Errorf("unit %s %w", unit, errUnitNotFound)
+			}
+		case "ActiveState":
+			state.ActiveState = value
+		case "SubState":
+			state.SubState = value
+		case "Result":
+			state.Result = value
+		case "Type":
+			state.ServiceType = value
+		case "NRestarts":
+			state.Restarts, _ = strconv.Atoi(value)
+		case "StateChangeTimestamp":
+			if seconds, err := strconv.ParseInt(strings.TrimPrefix(value, "@"), 10, 64); err == nil && seconds > 0 {
+				state.Since = time.Unix(seconds, 0)
+			}
+		}
+	}
+
+	return state, nil
+}
+
+func checkSystemd(ctx context.Context, check *HealthCheck) error {
+	unit := unitName(check.Endpoint)
+
+	conn, err := dbus.ConnectSystemBus(dbus.WithContext(ctx))
+	if err == nil {
+		defer conn.Close()
+		check.Unit, err = unitStateDBus(ctx, conn, unit)
+	}
+	if err != nil && !errors.Is(err, errUnitNotFound) {
+		state, fallbackErr := unitStateSystemctl(ctx, unit)
+		if fallbackErr != nil {
+			return fmt.Errorf("D-Bus: %v, %w", err, fallbackErr)
+		}
+		check.Unit, err = state, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	state := check.Unit
+	switch state.ActiveState {
+	case "active":
+	case "activating", "deactivating", "reloading":
+		check.Status = StatusDegraded
+		check.Message = fmt.Sprintf("%s (%s)", state.ActiveState, state.SubState)
+	case "inactive":
+		if state.ServiceType != "oneshot" || state.Result != "success" {
+			return fmt.Errorf("inactive (%s)", state.SubState)
+		}
+	default:
+		if state.Result != "" && state.Result != "success" {
+			return fmt.Errorf("%s (Result: %s)", state.ActiveState, state.Result)
+		}
+		return fmt.Errorf("%s (%s)", state.ActiveState, state.SubState)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeSystemctl = `#!/bin/sh
+case "$2" in
+nginx.service) printf 'Type=notify\nNRestarts=2\nResult=success\nLoadState=loaded\nActiveState=active\nSubState=running\nStateChangeTimestamp=@1760000000\n';;
+backup.service) printf 'Type=oneshot\nNRestarts=0\nResult=exit-code\nLoadState=loaded\nActiveState=failed\nSubState=failed\n';;
+cleanup.service) printf 'Type=oneshot\nNRestarts=0\nResult=success\nLoadState=loaded\nActiveState=inactive\nSubState=dead\n';;
+web.service) printf 'Type=simple\nResult=success\nLoadState=loaded\nActiveState=activating\nSubState=auto-restart\n';;
+*) printf 'LoadState=not-found\nActiveState=inactive\n';;
+esac
+`
+
+func TestCheckSystemdFallback(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "systemctl"), []byte(fakeSystemctl), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DBUS_SYSTEM_BUS_ADDRESS", "unix:path="+filepath.Join(dir, "no-bus"))
+
+	tests := []struct {
+		endpoint string
+		status   Status
+		restarts int
+		err      string
+	}{
+		{endpoint: "nginx", status: StatusUp, restarts: 2},
+		{endpoint: "cleanup.service", status: StatusUp},
+		{endpoint: "web", status: StatusDegraded},
+		{endpoint: "backup", err: "failed (Result: exit-code)"},
+		{endpoint: "missing", err: "unit missing.service not found"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.endpoint, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			check := HealthCheck{Endpoint: test.endpoint, Status: StatusUp}
+			err := checkSystemd(ctx, &check)
+
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("checkSystemd() = %v, want error containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkSystemd() = %v, want nil", err)
+			}
+			if check.Status != test.status || check.Unit.Restarts != test.restarts {
+				t.Errorf("got %s with %d restarts, want %s with %d", check.Status, check.Unit.Restarts, test.status, test.restarts)
+			}
+		})
+	}
+}
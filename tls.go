@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"time"
+)
+
+type Certificate struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"not_after"`
+}
+
+func (check HealthCheck) CertDaysLeft() int {
+	return int(math.Floor(time.Until(check.CertExpiry).Hours() / 24))
+}
+
+func downCertDays(check HealthCheck) int {
+	if check.DownCertDays > 0 {
+		return check.DownCertDays
+	}
+
+	return config.DownCertDays
+}
+
+func checkCertificates(check *HealthCheck, state *tls.ConnectionState) error {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	check.Certificates = nil
+	for _, cert := range state.PeerCertificates {
+		check.Certificates = append(check.Certificates, Certificate{
+			Subject:  cert.Subject.String(),
+			Issuer:   cert.Issuer.String(),
+			NotAfter: cert.NotAfter,
+		})
+		if check.CertExpiry.IsZero() || cert.NotAfter.Before(check.CertExpiry) {
+			check.CertExpiry = cert.NotAfter
+		}
+	}
+
+	daysLeft := check.CertDaysLeft()
+	if days := downCertDays(*check); daysLeft < days {
+		return fmt.Errorf("certificate expires in %d days", daysLeft)
+	}
+	if days := certDays(*check); check.Status == StatusUp && daysLeft < days {
+		check.Status = StatusDegraded
+		check.Message = fmt.Sprintf("Certificate expires in %d days", daysLeft)
+	}
+
+	return nil
+}
+
+func newTLSConfig(check HealthCheck) (*tls.Config, error) {
+	if check.CAFile == "" && check.CertFile == "" && check.KeyFile == "" && check.ServerName == "" && !check.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         check.ServerName,
+		InsecureSkipVerify: check.InsecureSkipVerify,
+	}
+
+	if check.CAFile != "" {
+		pem, err := os.ReadFile(check.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", check.CAFile)
+		}
+	}
+
+	if check.CertFile != "" || check.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(check.CertFile, check.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func setupTLS(check *HealthCheck) error {
+	tlsConfig, err := newTLSConfig(*check)
+	if err != nil || tlsConfig == nil {
+		return err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	check.tlsConfig = tlsConfig
+	check.client = &http.Client{Transport: transport}
+
+	return nil
+}
+
+func checkTLS(ctx context.Context, check *HealthCheck) error {
+	dialer := tls.Dialer{Config: check.tlsConfig}
+	conn, err := dialer.DialContext(ctx, "tcp", check.Endpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+
+	return checkCertificates(check, &state)
+}